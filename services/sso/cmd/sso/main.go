@@ -1,64 +1,161 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"os"
+	"os/signal"
+	"slices"
+	grpcapp "sso/internal/app/grpc"
+	httpapp "sso/internal/app/http"
 	"sso/internal/config"
-	"sso/internal/lib/logger/slogpretty"
-)
+	"sso/internal/grpc/admin"
+	"sso/internal/grpc/interceptors"
+	"sso/internal/lib/certs"
+	"sso/internal/lib/health"
+	"sso/internal/lib/logger"
+	"sso/internal/lib/logger/loglevel"
+	"sso/internal/lib/tracing"
+	"syscall"
+	"time"
 
-const (
-	envLocal = "local"
-	envDev   = "dev"
-	envProd  = "prod"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
 	cfg := config.MustLoad()
 
-	log := setupLogger(cfg.Env)
+	// level is shared by all handlers so it can be changed at runtime.
+	var level slog.LevelVar
+	level.Set(cfg.LogLevel())
+
+	log, closeLog, err := logger.New(cfg, &level)
+	if err != nil {
+		panic(err)
+	}
+	defer closeLog.Close()
 
 	log = log.With(
 		slog.String("env", cfg.Env),
 	)
 
 	log.Info("setup logger and config")
+	log.Debug("loaded config", slog.Any("config", cfg))
 
-}
+	levelCtl := loglevel.NewController(log, &level)
 
-func setupLogger(env string) *slog.Logger {
-	var log *slog.Logger
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Error("failed to setup tracing", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	healthSvc := health.New(log)
+
+	grpcOpts, err := grpcServerOptions(log, cfg.GRPC.TLS)
+	if err != nil {
+		log.Error("failed to setup grpc tls", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
 
-	switch env {
-	case envLocal:
-		log = setupPrettySlog(env)
-	case envDev:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	if cfg.Log.RequestDebug {
+		debugToken, debugClients := cfg.HTTP.AdminToken.Value(), cfg.GRPC.TLS.PrivilegedClients
+
+		grpcOpts = append(grpcOpts,
+			grpc.ChainUnaryInterceptor(interceptors.UnaryDebugLog(debugToken, debugClients)),
+			grpc.ChainStreamInterceptor(interceptors.StreamDebugLog(debugToken, debugClients)),
 		)
-	case envProd:
-		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
 	}
 
-	return log
+	application := grpcapp.New(log, cfg.GRPC.Port, grpcOpts...)
+	healthSvc.Register(application.Server())
+
+	// SetLogLevel is always privileged, so it only makes sense when some
+	// client certificate can pass the allowlist.
+	if tlsCfg := cfg.GRPC.TLS; tlsCfg.ClientCAFile != "" && len(tlsCfg.PrivilegedClients) > 0 {
+		admin.Register(application.Server(), levelCtl)
+	}
+
+	httpApp := httpapp.New(log, cfg.HTTP.Port)
+	httpApp.Handle("GET /healthz", healthSvc.LiveHandler())
+	httpApp.Handle("GET /readyz", healthSvc.ReadyHandler())
+
+	if token := cfg.HTTP.AdminToken.Value(); token != "" {
+		httpApp.Handle("/debug/loglevel", levelCtl.Handler(token))
+	}
+
+	reloader := config.NewReloader(log, cfg)
+	reloader.OnReload(func(c *config.Config) {
+		levelCtl.SetBase(c.LogLevel())
+	})
+
+	go func() {
+		if err := reloader.Run(ctx); err != nil {
+			log.Error("config reload is disabled", slog.String("error", err.Error()))
+		}
+	}()
 
+	go healthSvc.Run(ctx, cfg.Health.CheckInterval)
+	go application.MustRun()
+	go httpApp.MustRun()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+
+	sign := <-stop
+
+	log.Info("stopping application", slog.String("signal", sign.String()))
+
+	// Turn unready first so probes and balancers move traffic away.
+	healthSvc.Drain()
+	time.Sleep(cfg.Health.DrainDelay)
+	cancel()
+
+	application.Stop()
+	httpApp.Stop()
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		log.Error("failed to shutdown tracing", slog.String("error", err.Error()))
+	}
+
+	log.Info("application stopped")
 }
 
-func setupPrettySlog(env string) *slog.Logger {
-	opts := slogpretty.PrettyHandlerOptions{
-		SlogOpts: &slog.HandlerOptions{
-			Level: slog.LevelDebug,
-		},
+func grpcServerOptions(log *slog.Logger, cfg config.TLSConfig) ([]grpc.ServerOption, error) {
+	var opts []grpc.ServerOption
+
+	if !cfg.Enabled() {
+		log.Warn("grpc tls is disabled, credentials travel in plaintext")
+
+		return opts, nil
+	}
+
+	tlsCfg, err := certs.ServerConfig(log, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+
+	methods := cfg.PrivilegedMethods
+	if cfg.ClientCAFile != "" {
+		methods = append(slices.Clone(methods), admin.SetLogLevelMethod)
 	}
-	switch env {
-	case envLocal:
-		opts.SlogOpts.Level = slog.LevelDebug
-	case envDev:
-		opts.SlogOpts.Level = slog.LevelDebug
-	case envProd:
-		opts.SlogOpts.Level = slog.LevelInfo
+
+	if len(methods) > 0 {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(
+				interceptors.UnaryClientAllowlist(methods, cfg.PrivilegedClients),
+			),
+			grpc.ChainStreamInterceptor(
+				interceptors.StreamClientAllowlist(methods, cfg.PrivilegedClients),
+			),
+		)
 	}
-	handler := opts.NewPrettyHandler(os.Stdout)
 
-	return slog.New(handler)
+	return opts, nil
 }
@@ -0,0 +1,93 @@
+package grpcapp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"sso/internal/lib/health"
+)
+
+// TestApp_StopWithOpenWatch holds a health Watch stream open across the
+// drain sequence main runs on shutdown: Drain, then Stop.
+func TestApp_StopWithOpenWatch(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	app := New(log, 0)
+	app.stopTimeout = 100 * time.Millisecond
+
+	healthSvc := health.New(log)
+	healthSvc.Register(app.Server())
+
+	lis := bufconn.Listen(1 << 20)
+	served := make(chan error, 1)
+	go func() { served <- app.Server().Serve(lis) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go healthSvc.Run(ctx, time.Hour)
+
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx,
+		&healthpb.HealthCheckRequest{Service: health.AuthService})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.GetStatus() == healthpb.HealthCheckResponse_SERVING {
+			break
+		}
+	}
+
+	healthSvc.Drain()
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status after Drain = %v, want NOT_SERVING", resp.GetStatus())
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		app.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop() is blocked by the open Watch stream")
+	}
+
+	if _, err := stream.Recv(); err == nil {
+		t.Error("Watch stream still open after Stop")
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package httpapp
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sso/internal/lib/metrics"
+)
+
+func TestApp_Routes(t *testing.T) {
+	app := New(slog.New(slog.NewTextHandler(io.Discard, nil)), 0)
+	app.Handle("GET /healthz", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+
+	srv := httptest.NewServer(app.server.Handler)
+	t.Cleanup(srv.Close)
+
+	// Vectors are only exported once they have a series.
+	metrics.GRPCHandled.WithLabelValues("/test.Service/Method", "OK").Inc()
+	metrics.LoginSucceeded("test")
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		wantCode int
+		wantBody []string
+	}{
+		{
+			name:     "metrics",
+			method:   http.MethodGet,
+			path:     "/metrics",
+			wantCode: http.StatusOK,
+			wantBody: []string{
+				`sso_grpc_server_handled_total{code="OK",method="/test.Service/Method"}`,
+				`sso_auth_logins_total{app="test",reason="",result="success"}`,
+				"sso_auth_active_sessions",
+				"go_goroutines",
+			},
+		},
+		{name: "metrics is read-only", method: http.MethodPost, path: "/metrics", wantCode: http.StatusMethodNotAllowed},
+		{name: "extra route", method: http.MethodGet, path: "/healthz", wantCode: http.StatusOK, wantBody: []string{"ok"}},
+		{name: "unknown route", method: http.MethodGet, path: "/nope", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(string(body), want) {
+					t.Errorf("body does not contain %q", want)
+				}
+			}
+		})
+	}
+}
@@ -1,36 +1,465 @@
 package config
 
 import (
-	"github.com/ilyakaznacheev/cleanenv"
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+
+	"sso/internal/lib/secrets"
 )
 
+const (
+	EnvLocal = "local"
+	EnvDev   = "dev"
+	EnvProd  = "prod"
+)
+
+const (
+	minTokenTTL = time.Minute
+	maxTokenTTL = 30 * 24 * time.Hour
+)
+
+// Config is the sso configuration. Every field can be overridden by the
+// environment variable in its env tag; env values win over the config file.
 type Config struct {
-	Env         string     `yaml:"env" env-default:"local"`
-	StoragePath string     `yaml:"storage_path" env-required:"true"`
-	TokenTTL    string     `yaml:"token_ttl" env-default:"24h"`
-	GRPC        GRPCConfig `yaml:"grpc"`
+	Env         string        `yaml:"env" env:"SSO_ENV" env-default:"local"`
+	StoragePath string        `yaml:"storage_path" env:"SSO_STORAGE_PATH"`
+	TokenTTL    time.Duration `yaml:"token_ttl" env:"SSO_TOKEN_TTL" env-default:"24h"`
+	GRPC        GRPCConfig    `yaml:"grpc" env-prefix:"SSO_GRPC_"`
+	HTTP        HTTPConfig    `yaml:"http" env-prefix:"SSO_HTTP_"`
+	Tracing     TracingConfig `yaml:"tracing" env-prefix:"SSO_TRACING_"`
+	Health      HealthConfig  `yaml:"health" env-prefix:"SSO_HEALTH_"`
+	Log         LogConfig     `yaml:"log" env-prefix:"SSO_LOG_"`
+	Secrets     SecretsConfig `yaml:"secrets" env-prefix:"SSO_SECRETS_"`
+	// SigningKey signs issued tokens. Keep it out of the file: use a
+	// file://, env:// or vault:// reference. Literal values are rejected
+	// in prod.
+	SigningKey secrets.Secret `yaml:"signing_key" env:"SSO_SIGNING_KEY"`
+
+	path string
 }
 
 type GRPCConfig struct {
-	Port    int    `yaml:"port" env-default:"44044"`
-	Timeout string `yaml:"timeout" env-default:"10h"`
+	Port    int           `yaml:"port" env:"PORT" env-default:"44044"`
+	Timeout time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"10h"`
+	TLS     TLSConfig     `yaml:"tls" env-prefix:"TLS_"`
+}
+
+// TLSConfig enables TLS on the gRPC listener when CertFile is set, and
+// mutual TLS when ClientCAFile is set as well.
+type TLSConfig struct {
+	CertFile   string `yaml:"cert_file" env:"CERT_FILE"`
+	KeyFile    string `yaml:"key_file" env:"KEY_FILE"`
+	MinVersion string `yaml:"min_version" env:"MIN_VERSION" env-default:"1.2"`
+	// ClientCAFile verifies client certificates. Clients without one are
+	// still accepted unless RequireClientCert is set.
+	ClientCAFile      string `yaml:"client_ca_file" env:"CLIENT_CA_FILE"`
+	RequireClientCert bool   `yaml:"require_client_cert" env:"REQUIRE_CLIENT_CERT"`
+	// PrivilegedMethods (full gRPC method names, e.g. /auth.Auth/IsAdmin)
+	// may only be called by clients whose certificate CN or DNS SAN is in
+	// PrivilegedClients. /admin.Admin/SetLogLevel is always privileged and
+	// only served when both ClientCAFile and PrivilegedClients are set.
+	PrivilegedMethods []string `yaml:"privileged_methods" env:"PRIVILEGED_METHODS"`
+	PrivilegedClients []string `yaml:"privileged_clients" env:"PRIVILEGED_CLIENTS"`
+}
+
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+type HTTPConfig struct {
+	Port int `yaml:"port" env:"PORT" env-default:"9090"`
+	// AdminToken guards admin endpoints such as /debug/loglevel, which are
+	// not served when it is empty.
+	AdminToken secrets.Secret `yaml:"admin_token" env:"ADMIN_TOKEN"`
+}
+
+type TracingConfig struct {
+	// Exporter is one of none, otlp or stdout.
+	Exporter    string  `yaml:"exporter" env:"EXPORTER" env-default:"none"`
+	ServiceName string  `yaml:"service_name" env:"SERVICE_NAME" env-default:"sso"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"SAMPLE_RATIO" env-default:"1"`
+	// Endpoint is the OTLP gRPC collector address, e.g. localhost:4317.
+	Endpoint string `yaml:"endpoint" env:"ENDPOINT" env-default:"localhost:4317"`
+	Insecure bool   `yaml:"insecure" env:"INSECURE"`
+	// File is where the stdout exporter writes; empty means stdout.
+	File string `yaml:"file" env:"FILE"`
+}
+
+type HealthConfig struct {
+	CheckInterval time.Duration `yaml:"check_interval" env:"CHECK_INTERVAL" env-default:"5s"`
+	// DrainDelay is how long sso reports not ready before it stops serving.
+	DrainDelay time.Duration `yaml:"drain_delay" env:"DRAIN_DELAY" env-default:"5s"`
+}
+
+const (
+	LogFormatPretty = "pretty"
+	LogFormatJSON   = "json"
+	LogFormatText   = "text"
+)
+
+const (
+	LogOutputStdout = "stdout"
+	LogOutputStderr = "stderr"
+	LogOutputFile   = "file"
+)
+
+type LogConfig struct {
+	// Level is debug, info, warn or error. Empty means debug for local and
+	// dev, info for prod.
+	Level string `yaml:"level" env:"LEVEL"`
+	// Format is pretty, json or text. Empty means pretty for local, json
+	// otherwise.
+	Format string `yaml:"format" env:"FORMAT"`
+	// Outputs defaults to stdout only.
+	Outputs []LogOutput `yaml:"outputs"`
+	// Packages overrides Level for packages and their subpackages, keyed by
+	// import path, e.g. sso/internal/grpc/interceptors: warn.
+	Packages map[string]string `yaml:"packages" env:"PACKAGES"`
+	Redact   RedactConfig      `yaml:"redact" env-prefix:"REDACT_"`
+	// RequestDebug lets a gRPC call turn on debug logging for itself with
+	// the x-debug-log: true metadata header. Only trusted callers may do
+	// so: those sending http.admin_token in x-debug-token or presenting a
+	// client certificate listed in grpc.tls.privileged_clients.
+	RequestDebug bool `yaml:"request_debug" env:"REQUEST_DEBUG"`
+}
+
+// RedactConfig masks secrets and personal data (emails, phones, JWTs) in
+// every log record, whatever the format.
+type RedactConfig struct {
+	Enabled bool `yaml:"enabled" env:"ENABLED"`
+	// Keys are attribute keys whose values are always fully replaced.
+	Keys []string `yaml:"keys" env:"KEYS" env-default:"password,token,refresh_token"`
+}
+
+type LogOutput struct {
+	// Type is stdout, stderr or file.
+	Type string `yaml:"type"`
+	Path string `yaml:"path"`
+	// MaxSizeMB rotates the file when it grows past this size (default 100).
+	MaxSizeMB int `yaml:"max_size_mb"`
+	// MaxAge deletes rotated files older than this, rounded up to days.
+	// Zero keeps them.
+	MaxAge     time.Duration `yaml:"max_age"`
+	MaxBackups int           `yaml:"max_backups"`
+	Compress   bool          `yaml:"compress"`
+}
+
+// PackageLevels parses Packages.
+func (c LogConfig) PackageLevels() (map[string]slog.Level, error) {
+	res := make(map[string]slog.Level, len(c.Packages))
+
+	for pkg, s := range c.Packages {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(s)); err != nil {
+			return nil, fmt.Errorf("log.packages.%s: unknown level %q", pkg, s)
+		}
+		res[pkg] = level
+	}
+
+	return res, nil
+}
+
+// SecretsConfig configures how secret references in the config are resolved.
+type SecretsConfig struct {
+	// VaultAddr enables vault:// references, e.g. http://127.0.0.1:8200.
+	VaultAddr  string         `yaml:"vault_addr" env:"VAULT_ADDR"`
+	VaultToken secrets.Secret `yaml:"vault_token" env:"VAULT_TOKEN" env-default:"env://VAULT_TOKEN"`
+	Timeout    time.Duration  `yaml:"timeout" env:"TIMEOUT" env-default:"5s"`
 }
 
+// MustLoad loads the config from the path given by --config or CONFIG_PATH
+// and panics if it cannot be loaded or is invalid.
 func MustLoad() *Config {
+	path := fetchConfigPath()
+	if path == "" {
+		panic("config path is empty: set --config or CONFIG_PATH")
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		panic(err)
+	}
+
+	return cfg
+}
+
+// Load reads the config file at path, applies environment overrides,
+// resolves secret references and validates the result.
+func Load(path string) (*Config, error) {
+	const op = "config.Load"
+
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("%s: config file: %w", op, err)
+	}
+
 	var cfg Config
 
-	configFile := os.Getenv("CONFIG_PATH")
-	if configFile == "" {
-		log.Fatal("CONFIG_PATH environment variable is not set")
+	if err := cleanenv.ReadConfig(path, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Collect secret and validation errors together so one run reports
+	// every problem. Resolving needs a positive secrets.timeout, which
+	// Validate reports otherwise.
+	errs := cfg.secretRefErrors()
+	if cfg.Secrets.Timeout > 0 {
+		errs = append(errs, cfg.resolveSecrets()...)
+	}
+	errs = append(errs, cfg.Validate())
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("%s: invalid config: %w", op, err)
+	}
+
+	cfg.path = path
+
+	return &cfg, nil
+}
+
+type secretField struct {
+	key string
+	val *secrets.Secret
+}
+
+// secretFields lists the secrets resolved by Load, by config key.
+func (c *Config) secretFields() []secretField {
+	return []secretField{
+		{key: "signing_key", val: &c.SigningKey},
+		{key: "http.admin_token", val: &c.HTTP.AdminToken},
+	}
+}
+
+// secretRefErrors rejects secrets given as literal values in prod, where
+// they must come from a file://, env:// or vault:// reference. It must run
+// before the references are resolved.
+func (c *Config) secretRefErrors() []error {
+	if c.Env != EnvProd {
+		return nil
+	}
+
+	var errs []error
+	for _, f := range c.secretFields() {
+		if *f.val != "" && !f.val.IsReference() {
+			errs = append(errs, fmt.Errorf("%s: literal values are not allowed in prod, use a file://, env:// or vault:// reference", f.key))
+		}
+	}
+
+	return errs
+}
+
+func (c *Config) resolveSecrets() []error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Secrets.Timeout)
+	defer cancel()
+
+	r := secrets.NewResolver()
+
+	var errs []error
+
+	if c.Secrets.VaultAddr != "" {
+		if err := r.Resolve(ctx, &c.Secrets.VaultToken); err != nil {
+			errs = append(errs, fmt.Errorf("secrets.vault_token: %w", err))
+		} else {
+			client := &http.Client{Timeout: c.Secrets.Timeout}
+			r.Register("vault", secrets.NewVault(c.Secrets.VaultAddr, c.Secrets.VaultToken.Value(), client))
+		}
+	}
+
+	for _, f := range c.secretFields() {
+		if err := r.Resolve(ctx, f.val); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.key, err))
+		}
+	}
+
+	return errs
+}
+
+// LogFormat returns the configured log format or the default for Env.
+func (c *Config) LogFormat() string {
+	if c.Log.Format != "" {
+		return c.Log.Format
+	}
+
+	if c.Env == EnvLocal {
+		return LogFormatPretty
+	}
+
+	return LogFormatJSON
+}
+
+// Path returns the file the config was loaded from.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// LogLevel returns the configured log level or the default for Env.
+func (c *Config) LogLevel() slog.Level {
+	var level slog.Level
+	if c.Log.Level != "" && level.UnmarshalText([]byte(c.Log.Level)) == nil {
+		return level
+	}
+
+	if c.Env == EnvProd {
+		return slog.LevelInfo
+	}
+
+	return slog.LevelDebug
+}
+
+// Validate reports every problem with the config at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.Env {
+	case EnvLocal, EnvDev, EnvProd:
+	default:
+		errs = append(errs, fmt.Errorf("env: unknown value %q (want %s, %s or %s)", c.Env, EnvLocal, EnvDev, EnvProd))
+	}
+
+	if c.StoragePath == "" {
+		errs = append(errs, errors.New("storage_path: must not be empty"))
+	}
+
+	if c.TokenTTL < minTokenTTL || c.TokenTTL > maxTokenTTL {
+		errs = append(errs, fmt.Errorf("token_ttl: %s is out of range [%s, %s]", c.TokenTTL, minTokenTTL, maxTokenTTL))
+	}
+
+	errs = append(errs, validatePort("grpc.port", c.GRPC.Port), validatePort("http.port", c.HTTP.Port))
+
+	if c.GRPC.Port == c.HTTP.Port {
+		errs = append(errs, fmt.Errorf("http.port: %d is already used by grpc.port", c.HTTP.Port))
+	}
+
+	if c.GRPC.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("grpc.timeout: must be positive, got %s", c.GRPC.Timeout))
+	}
+
+	errs = append(errs, c.GRPC.TLS.validate()...)
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter: unknown value %q (want none, otlp or stdout)", c.Tracing.Exporter))
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_ratio: %v is out of range [0, 1]", c.Tracing.SampleRatio))
+	}
+
+	if c.Health.CheckInterval <= 0 {
+		errs = append(errs, fmt.Errorf("health.check_interval: must be positive, got %s", c.Health.CheckInterval))
+	}
+
+	if c.Health.DrainDelay < 0 {
+		errs = append(errs, fmt.Errorf("health.drain_delay: must not be negative, got %s", c.Health.DrainDelay))
+	}
+
+	if c.Secrets.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("secrets.timeout: must be positive, got %s", c.Secrets.Timeout))
+	}
+
+	if c.Log.Level != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+			errs = append(errs, fmt.Errorf("log.level: unknown value %q", c.Log.Level))
+		}
+	}
+
+	errs = append(errs, c.Log.validate()...)
+
+	if c.Log.RequestDebug && c.HTTP.AdminToken == "" &&
+		(len(c.GRPC.TLS.PrivilegedClients) == 0 || c.GRPC.TLS.ClientCAFile == "") {
+		errs = append(errs, errors.New("log.request_debug: requires http.admin_token or grpc.tls.privileged_clients with client_ca_file"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c TLSConfig) validate() []error {
+	var errs []error
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		errs = append(errs, errors.New("grpc.tls: cert_file and key_file must be set together"))
+	}
+
+	switch c.MinVersion {
+	case "1.2", "1.3":
+	default:
+		errs = append(errs, fmt.Errorf("grpc.tls.min_version: unsupported value %q (want 1.2 or 1.3)", c.MinVersion))
+	}
+
+	if c.ClientCAFile != "" && !c.Enabled() {
+		errs = append(errs, errors.New("grpc.tls.client_ca_file: requires cert_file and key_file"))
+	}
+
+	if c.RequireClientCert && c.ClientCAFile == "" {
+		errs = append(errs, errors.New("grpc.tls.require_client_cert: requires client_ca_file"))
+	}
+
+	if len(c.PrivilegedMethods) > 0 && c.ClientCAFile == "" {
+		errs = append(errs, errors.New("grpc.tls.privileged_methods: requires client_ca_file"))
+	}
+
+	return errs
+}
+
+func (c LogConfig) validate() []error {
+	var errs []error
+
+	switch c.Format {
+	case "", LogFormatPretty, LogFormatJSON, LogFormatText:
+	default:
+		errs = append(errs, fmt.Errorf("log.format: unknown value %q (want pretty, json or text)", c.Format))
+	}
+
+	for i, o := range c.Outputs {
+		switch o.Type {
+		case LogOutputStdout, LogOutputStderr:
+		case LogOutputFile:
+			if o.Path == "" {
+				errs = append(errs, fmt.Errorf("log.outputs[%d].path: required for file output", i))
+			}
+			if o.MaxSizeMB < 0 || o.MaxAge < 0 || o.MaxBackups < 0 {
+				errs = append(errs, fmt.Errorf("log.outputs[%d]: rotation limits must not be negative", i))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("log.outputs[%d].type: unknown value %q (want stdout, stderr or file)", i, o.Type))
+		}
+	}
+
+	if _, err := c.PackageLevels(); err != nil {
+		errs = append(errs, err)
 	}
 
-	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		log.Fatalf("CONFIG_PATH does not exist %s:", configFile)
+	return errs
+}
+
+func validatePort(field string, port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("%s: %d is out of range [1, 65535]", field, port)
 	}
-	if err := cleanenv.ReadConfig(configFile, &cfg); err != nil {
-		log.Fatalf("error loading config %s:", err)
+
+	return nil
+}
+
+// fetchConfigPath returns the config path from the --config flag, falling
+// back to the CONFIG_PATH env var.
+func fetchConfigPath() string {
+	var res string
+
+	flag.StringVar(&res, "config", "", "path to config file")
+	flag.Parse()
+
+	if res == "" {
+		res = os.Getenv("CONFIG_PATH")
 	}
-	return &cfg
+
+	return res
 }
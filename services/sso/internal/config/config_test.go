@@ -0,0 +1,335 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const validYAML = `
+env: dev
+storage_path: ./storage/sso.db
+token_ttl: 1h
+grpc:
+  port: 44044
+  timeout: 5s
+http:
+  port: 9090
+`
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		env     map[string]string
+		check   func(t *testing.T, cfg *Config)
+		wantErr []string
+	}{
+		{
+			name: "file values and defaults",
+			body: validYAML,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Env != EnvDev || cfg.StoragePath != "./storage/sso.db" {
+					t.Errorf("env, storage_path = %q, %q", cfg.Env, cfg.StoragePath)
+				}
+				if cfg.TokenTTL != time.Hour || cfg.GRPC.Timeout != 5*time.Second {
+					t.Errorf("token_ttl, grpc.timeout = %s, %s", cfg.TokenTTL, cfg.GRPC.Timeout)
+				}
+				if cfg.Health.CheckInterval != 5*time.Second || cfg.Tracing.Exporter != "none" {
+					t.Errorf("defaults not applied: %+v, %+v", cfg.Health, cfg.Tracing)
+				}
+			},
+		},
+		{
+			name: "env overrides file",
+			body: validYAML,
+			env: map[string]string{
+				"SSO_TOKEN_TTL":        "2h",
+				"SSO_GRPC_PORT":        "50051",
+				"SSO_HTTP_ADMIN_TOKEN": "s3cret",
+				"SSO_LOG_LEVEL":        "warn",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.TokenTTL != 2*time.Hour {
+					t.Errorf("token_ttl = %s, want 2h", cfg.TokenTTL)
+				}
+				if cfg.GRPC.Port != 50051 {
+					t.Errorf("grpc.port = %d, want 50051", cfg.GRPC.Port)
+				}
+				if cfg.HTTP.AdminToken.Value() != "s3cret" {
+					t.Errorf("http.admin_token not taken from env")
+				}
+				if cfg.Log.Level != "warn" {
+					t.Errorf("log.level = %q, want warn", cfg.Log.Level)
+				}
+			},
+		},
+		{
+			name: "storage path from env only",
+			body: "env: local\n",
+			env:  map[string]string{"SSO_STORAGE_PATH": "mock"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.StoragePath != "mock" {
+					t.Errorf("storage_path = %q, want mock", cfg.StoragePath)
+				}
+			},
+		},
+		{
+			name: "secret reference resolved",
+			body: validYAML + "signing_key: env://TEST_SSO_SIGNING_KEY\n",
+			env:  map[string]string{"TEST_SSO_SIGNING_KEY": "key"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.SigningKey.Value() != "key" {
+					t.Errorf("signing_key not resolved")
+				}
+			},
+		},
+		{
+			name: "all errors reported",
+			body: "env: staging\ntoken_ttl: 1s\ngrpc:\n  port: 70000\n",
+			wantErr: []string{
+				"env: unknown value",
+				"storage_path: must not be empty",
+				"token_ttl: 1s is out of range",
+				"grpc.port: 70000 is out of range",
+			},
+		},
+		{
+			name:    "bad env override",
+			body:    validYAML,
+			env:     map[string]string{"SSO_TOKEN_TTL": "forever"},
+			wantErr: []string{"config.Load"},
+		},
+		{
+			name:    "missing secret",
+			body:    validYAML + "signing_key: env://TEST_SSO_UNSET\n",
+			wantErr: []string{"signing_key"},
+		},
+		{
+			name: "secret and validation errors together",
+			body: strings.Replace(validYAML, "token_ttl: 1h", "token_ttl: 1s", 1) +
+				"signing_key: env://TEST_SSO_UNSET\n",
+			env:     map[string]string{"SSO_HTTP_ADMIN_TOKEN": "file:///nonexistent/token"},
+			wantErr: []string{"signing_key: ", "http.admin_token: ", "token_ttl: 1s is out of range"},
+		},
+		{
+			name:    "non-positive secrets timeout",
+			body:    validYAML + "signing_key: env://TEST_SSO_UNSET\nsecrets:\n  timeout: -1s\n",
+			wantErr: []string{"secrets.timeout: must be positive"},
+		},
+		{
+			name: "literal secrets rejected in prod",
+			body: strings.Replace(validYAML, "env: dev", "env: prod", 1) +
+				"signing_key: inline-key\n",
+			env: map[string]string{"SSO_HTTP_ADMIN_TOKEN": "inline-token"},
+			wantErr: []string{
+				"signing_key: literal values are not allowed in prod",
+				"http.admin_token: literal values are not allowed in prod",
+			},
+		},
+		{
+			name: "references accepted in prod",
+			body: strings.Replace(validYAML, "env: dev", "env: prod", 1) +
+				"signing_key: env://TEST_SSO_SIGNING_KEY\n",
+			env: map[string]string{"TEST_SSO_SIGNING_KEY": "key"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.SigningKey.Value() != "key" {
+					t.Errorf("signing_key not resolved")
+				}
+			},
+		},
+		{
+			name: "literal secrets allowed outside prod",
+			body: validYAML + "signing_key: inline-key\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.SigningKey.Value() != "inline-key" {
+					t.Errorf("signing_key = %q, want the literal", cfg.SigningKey.Value())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			path := writeConfig(t, tt.body)
+
+			cfg, err := Load(path)
+			if len(tt.wantErr) > 0 {
+				if err == nil {
+					t.Fatal("Load() error = nil, want error")
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("Load() error = %q, want it to contain %q", err, want)
+					}
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Path() != path {
+				t.Errorf("Path() = %q, want %q", cfg.Path(), path)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "nope.yaml")); err == nil {
+		t.Fatal("Load() error = nil, want error")
+	}
+}
+
+func validConfig() Config {
+	return Config{
+		Env:         EnvLocal,
+		StoragePath: "mock",
+		TokenTTL:    time.Hour,
+		GRPC:        GRPCConfig{Port: 44044, Timeout: time.Second, TLS: TLSConfig{MinVersion: "1.2"}},
+		HTTP:        HTTPConfig{Port: 9090},
+		Tracing:     TracingConfig{Exporter: "none", SampleRatio: 1},
+		Health:      HealthConfig{CheckInterval: time.Second},
+		Secrets:     SecretsConfig{Timeout: time.Second},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr []string
+	}{
+		{
+			name:   "valid",
+			modify: func(*Config) {},
+		},
+		{
+			name:    "port clash",
+			modify:  func(c *Config) { c.HTTP.Port = c.GRPC.Port },
+			wantErr: []string{"http.port: 44044 is already used by grpc.port"},
+		},
+		{
+			name: "token ttl bounds",
+			modify: func(c *Config) {
+				c.TokenTTL = 31 * 24 * time.Hour
+			},
+			wantErr: []string{"token_ttl"},
+		},
+		{
+			name: "tls",
+			modify: func(c *Config) {
+				c.GRPC.TLS = TLSConfig{
+					CertFile:          "server.crt",
+					MinVersion:        "1.1",
+					RequireClientCert: true,
+					PrivilegedMethods: []string{"/auth.Auth/IsAdmin"},
+				}
+			},
+			wantErr: []string{
+				"grpc.tls: cert_file and key_file must be set together",
+				"grpc.tls.min_version",
+				"grpc.tls.require_client_cert",
+				"grpc.tls.privileged_methods",
+			},
+		},
+		{
+			name: "log",
+			modify: func(c *Config) {
+				c.Log = LogConfig{
+					Level:    "verbose",
+					Format:   "xml",
+					Outputs:  []LogOutput{{Type: LogOutputFile}, {Type: "syslog"}},
+					Packages: map[string]string{"sso/internal/app": "loud"},
+				}
+			},
+			wantErr: []string{
+				"log.level",
+				"log.format",
+				"log.outputs[0].path",
+				"log.outputs[1].type",
+				"log.packages.sso/internal/app",
+			},
+		},
+		{
+			name:    "request debug without a way to authorize it",
+			modify:  func(c *Config) { c.Log.RequestDebug = true },
+			wantErr: []string{"log.request_debug"},
+		},
+		{
+			name: "request debug with admin token",
+			modify: func(c *Config) {
+				c.Log.RequestDebug = true
+				c.HTTP.AdminToken = "s3cret"
+			},
+		},
+		{
+			name: "many at once",
+			modify: func(c *Config) {
+				c.Env = ""
+				c.StoragePath = ""
+				c.GRPC.Timeout = 0
+				c.Tracing.Exporter = "jaeger"
+				c.Tracing.SampleRatio = 2
+				c.Health.CheckInterval = 0
+				c.Health.DrainDelay = -time.Second
+				c.Secrets.Timeout = 0
+			},
+			wantErr: []string{
+				"env:",
+				"storage_path:",
+				"grpc.timeout:",
+				"tracing.exporter:",
+				"tracing.sample_ratio:",
+				"health.check_interval:",
+				"health.drain_delay:",
+				"secrets.timeout:",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Validate() error = nil, want error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
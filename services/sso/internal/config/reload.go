@@ -0,0 +1,222 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce coalesces the burst of events editors produce on save.
+const reloadDebounce = 200 * time.Millisecond
+
+// Reloader re-reads the config file when it changes or on SIGHUP and swaps
+// in the new config if only live settings changed. The only live setting
+// is log.level; anything else requires a restart.
+type Reloader struct {
+	log *slog.Logger
+	cur atomic.Pointer[Config]
+
+	mu        sync.Mutex
+	listeners []func(*Config)
+}
+
+func NewReloader(log *slog.Logger, cfg *Config) *Reloader {
+	r := &Reloader{log: log}
+	r.cur.Store(cfg)
+
+	return r
+}
+
+// Current returns the config in effect.
+func (r *Reloader) Current() *Config {
+	return r.cur.Load()
+}
+
+// OnReload registers fn to be called with every config that is swapped in.
+func (r *Reloader) OnReload(fn func(*Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.listeners = append(r.listeners, fn)
+}
+
+// Run watches the config file and SIGHUP until ctx is done.
+func (r *Reloader) Run(ctx context.Context) error {
+	const op = "config.Reloader.Run"
+
+	path := r.Current().Path()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer watcher.Close()
+
+	// Watch the directory: editors replace the file instead of writing to
+	// it, which drops a watch on the file itself, and a Kubernetes ConfigMap
+	// update only swaps the ..data symlink next to it. Any event in the
+	// directory triggers a check; the content hash tells if the file changed.
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	sum, err := fileHash(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hup:
+			r.log.Info("received SIGHUP, reloading config")
+			r.reload()
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !ev.Has(fsnotify.Chmod) {
+				debounce = time.After(reloadDebounce)
+			}
+		case <-debounce:
+			newSum, err := fileHash(path)
+			if err != nil {
+				r.log.Error("failed to read config file", slog.String("error", err.Error()))
+
+				continue
+			}
+			if newSum == sum {
+				continue
+			}
+			sum = newSum
+
+			r.log.Info("config file changed, reloading config")
+			r.reload()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			r.log.Error("config watcher error", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// Reload loads the config file again and applies it. It returns an error
+// and keeps the current config if the new one is invalid or changes a
+// restart-only setting.
+func (r *Reloader) Reload() error {
+	const op = "config.Reloader.Reload"
+
+	old := r.Current()
+
+	cfg, err := Load(old.Path())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if changed := restartOnlyChanges(old, cfg); len(changed) > 0 {
+		return fmt.Errorf("%s: settings require a restart: %v", op, changed)
+	}
+
+	r.cur.Store(cfg)
+
+	r.mu.Lock()
+	listeners := r.listeners
+	r.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(cfg)
+	}
+
+	return nil
+}
+
+func (r *Reloader) reload() {
+	if err := r.Reload(); err != nil {
+		r.log.Error("config reload rejected", slog.String("error", err.Error()))
+
+		return
+	}
+
+	cfg := r.Current()
+	r.log.Info("config reloaded", slog.String("log_level", cfg.LogLevel().String()))
+}
+
+// fileHash returns the SHA-256 of the file at path, following symlinks.
+func fileHash(path string) ([sha256.Size]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	return sha256.Sum256(b), nil
+}
+
+// restartOnlyChanges lists the settings that differ between old and cfg
+// and cannot be applied to a running process.
+func restartOnlyChanges(old, cfg *Config) []string {
+	var changed []string
+
+	if old.Env != cfg.Env {
+		changed = append(changed, "env")
+	}
+	if old.StoragePath != cfg.StoragePath {
+		changed = append(changed, "storage_path")
+	}
+	if old.TokenTTL != cfg.TokenTTL {
+		changed = append(changed, "token_ttl")
+	}
+	if !reflect.DeepEqual(old.GRPC, cfg.GRPC) {
+		changed = append(changed, "grpc")
+	}
+	if old.HTTP != cfg.HTTP {
+		changed = append(changed, "http")
+	}
+	if old.Tracing != cfg.Tracing {
+		changed = append(changed, "tracing")
+	}
+	if old.Health != cfg.Health {
+		changed = append(changed, "health")
+	}
+	if old.Log.Format != cfg.Log.Format {
+		changed = append(changed, "log.format")
+	}
+	if !reflect.DeepEqual(old.Log.Outputs, cfg.Log.Outputs) {
+		changed = append(changed, "log.outputs")
+	}
+	if !reflect.DeepEqual(old.Log.Packages, cfg.Log.Packages) {
+		changed = append(changed, "log.packages")
+	}
+	if old.Log.RequestDebug != cfg.Log.RequestDebug {
+		changed = append(changed, "log.request_debug")
+	}
+	if !reflect.DeepEqual(old.Log.Redact, cfg.Log.Redact) {
+		changed = append(changed, "log.redact")
+	}
+	if old.Secrets != cfg.Secrets {
+		changed = append(changed, "secrets")
+	}
+	if old.SigningKey != cfg.SigningKey {
+		changed = append(changed, "signing_key")
+	}
+
+	return changed
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestReloader(t *testing.T, path string) *Reloader {
+	t.Helper()
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewReloader(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+}
+
+func TestReloader_Reload(t *testing.T) {
+	tests := []struct {
+		name    string
+		replace [2]string
+		wantErr string
+	}{
+		{name: "log level is live", replace: [2]string{"http:", "log:\n  level: warn\nhttp:"}},
+		{name: "token ttl needs restart", replace: [2]string{"token_ttl: 1h", "token_ttl: 2h"}, wantErr: "token_ttl"},
+		{name: "grpc port needs restart", replace: [2]string{"port: 44044", "port: 44045"}, wantErr: "grpc"},
+		{name: "invalid config", replace: [2]string{"env: dev", "env: staging"}, wantErr: "env"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, validYAML)
+			r := newTestReloader(t, path)
+			old := r.Current()
+
+			var got *Config
+			r.OnReload(func(c *Config) { got = c })
+
+			body := strings.Replace(validYAML, tt.replace[0], tt.replace[1], 1)
+			if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			err := r.Reload()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Reload() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				if r.Current() != old || got != nil {
+					t.Error("rejected config was applied")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Reload() error = %v", err)
+			}
+			if got == nil || r.Current() != got {
+				t.Fatal("listener not called with the current config")
+			}
+			if got.LogLevel() != slog.LevelWarn {
+				t.Errorf("LogLevel() = %s, want WARN", got.LogLevel())
+			}
+		})
+	}
+}
+
+// TestReloader_RunConfigMapSwap lays the config out the way a Kubernetes
+// ConfigMap volume does and swaps the ..data symlink.
+func TestReloader_RunConfigMapSwap(t *testing.T) {
+	dir := t.TempDir()
+
+	writeVersion := func(name, body string) {
+		t.Helper()
+
+		if err := os.Mkdir(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "config.yaml"), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeVersion("..v1", validYAML)
+	if err := os.Symlink("..v1", filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.Symlink(filepath.Join("..data", "config.yaml"), path); err != nil {
+		t.Fatal(err)
+	}
+
+	r := newTestReloader(t, path)
+
+	reloaded := make(chan *Config, 1)
+	r.OnReload(func(c *Config) { reloaded <- c })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- r.Run(ctx) }()
+
+	// Give the watcher time to start before the swap.
+	time.Sleep(100 * time.Millisecond)
+
+	writeVersion("..v2", strings.Replace(validYAML, "http:", "log:\n  level: error\nhttp:", 1))
+	if err := os.Symlink("..v2", filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case c := <-reloaded:
+		if c.LogLevel() != slog.LevelError {
+			t.Errorf("LogLevel() = %s, want ERROR", c.LogLevel())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded after the ..data swap")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Package admin serves the operator-only gRPC service.
+package admin
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"sso/internal/lib/logger/loglevel"
+)
+
+const (
+	// ServiceName is the fully-qualified name of the admin gRPC service.
+	ServiceName = "admin.Admin"
+	// SetLogLevelMethod is the full method name, as used by
+	// grpc.tls.privileged_methods and the client allowlist.
+	SetLogLevelMethod = "/" + ServiceName + "/SetLogLevel"
+)
+
+// Server is the server API for the admin service. It has no .proto: the
+// service is registered by hand and exchanges google.protobuf.Struct
+// messages, which any gRPC client can build without generated code.
+//
+// SetLogLevel takes {"level": "debug", "ttl": "5m"} and returns the level now
+// in effect, plus "revert_at" (RFC 3339) while a temporary level is set. An
+// empty ttl keeps the level until the next change.
+type Server interface {
+	SetLogLevel(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+type serverAPI struct {
+	levels *loglevel.Controller
+}
+
+// Register adds the admin service to gRPCServer. It performs no access
+// checks of its own: SetLogLevelMethod must be covered by the client
+// allowlist.
+func Register(gRPCServer *grpc.Server, levels *loglevel.Controller) {
+	gRPCServer.RegisterService(&serviceDesc, &serverAPI{levels: levels})
+}
+
+func (s *serverAPI) SetLogLevel(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	fields := req.GetFields()
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(fields["level"].GetStringValue())); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "unknown level")
+	}
+
+	var ttl time.Duration
+	if v := fields["ttl"].GetStringValue(); v != "" {
+		var err error
+		ttl, err = time.ParseDuration(v)
+		if err != nil || ttl < 0 || ttl > loglevel.MaxTTL {
+			return nil, status.Error(codes.InvalidArgument, "ttl must be a duration between 0 and 24h")
+		}
+	}
+
+	s.levels.Set(level, ttl)
+
+	current, revertAt := s.levels.Get()
+
+	resp := map[string]any{"level": current.String()}
+	if !revertAt.IsZero() {
+		resp["revert_at"] = revertAt.UTC().Format(time.RFC3339)
+	}
+
+	return structpb.NewStruct(resp)
+}
+
+func setLogLevelHandler(
+	srv any,
+	ctx context.Context,
+	dec func(any) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (any, error) {
+	in := new(structpb.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	if interceptor == nil {
+		return srv.(Server).SetLogLevel(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: SetLogLevelMethod}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(Server).SetLogLevel(ctx, req.(*structpb.Struct))
+	}
+
+	return interceptor(ctx, in, info, handler)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*Server)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "SetLogLevel", Handler: setLogLevelHandler},
+	},
+	Metadata: "internal/grpc/admin/admin.go",
+}
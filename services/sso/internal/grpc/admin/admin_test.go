@@ -0,0 +1,180 @@
+package admin
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"sso/internal/config"
+	"sso/internal/grpc/interceptors"
+	"sso/internal/lib/certs"
+	"sso/internal/lib/certs/certstest"
+	"sso/internal/lib/logger/loglevel"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// serve registers the admin service on srv, serves it over bufconn and
+// returns a client connection using creds.
+func serve(t *testing.T, srv *grpc.Server, levels *loglevel.Controller, creds credentials.TransportCredentials) *grpc.ClientConn {
+	t.Helper()
+
+	Register(srv, levels)
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(creds),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func setLogLevel(conn *grpc.ClientConn, fields map[string]any) (*structpb.Struct, error) {
+	req, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(structpb.Struct)
+	if err := conn.Invoke(context.Background(), SetLogLevelMethod, req, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func TestSetLogLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       map[string]any
+		wantCode  codes.Code
+		wantLevel slog.Level
+		temporary bool
+	}{
+		{name: "temporary", req: map[string]any{"level": "debug", "ttl": "5m"}, wantLevel: slog.LevelDebug, temporary: true},
+		{name: "permanent", req: map[string]any{"level": "warn"}, wantLevel: slog.LevelWarn},
+		{name: "unknown level", req: map[string]any{"level": "loud"}, wantCode: codes.InvalidArgument, wantLevel: slog.LevelInfo},
+		{name: "missing level", req: map[string]any{"ttl": "5m"}, wantCode: codes.InvalidArgument, wantLevel: slog.LevelInfo},
+		{name: "bad ttl", req: map[string]any{"level": "debug", "ttl": "soon"}, wantCode: codes.InvalidArgument, wantLevel: slog.LevelInfo},
+		{name: "negative ttl", req: map[string]any{"level": "debug", "ttl": "-1m"}, wantCode: codes.InvalidArgument, wantLevel: slog.LevelInfo},
+		{name: "ttl over max", req: map[string]any{"level": "debug", "ttl": "25h"}, wantCode: codes.InvalidArgument, wantLevel: slog.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var level slog.LevelVar
+			levels := loglevel.NewController(discardLogger(), &level)
+			conn := serve(t, grpc.NewServer(), levels, insecure.NewCredentials())
+
+			resp, err := setLogLevel(conn, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("SetLogLevel() code = %v, want %v (%v)", got, tt.wantCode, err)
+			}
+
+			if level.Level() != tt.wantLevel {
+				t.Errorf("level = %s, want %s", level.Level(), tt.wantLevel)
+			}
+			if err != nil {
+				return
+			}
+
+			fields := resp.GetFields()
+			if got := fields["level"].GetStringValue(); got != tt.wantLevel.String() {
+				t.Errorf("response level = %q, want %q", got, tt.wantLevel)
+			}
+
+			revertAt, ok := fields["revert_at"]
+			if ok != tt.temporary {
+				t.Fatalf("response has revert_at = %v, want %v", ok, tt.temporary)
+			}
+			if ok {
+				at, err := time.Parse(time.RFC3339, revertAt.GetStringValue())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if d := time.Until(at); d <= 0 || d > 5*time.Minute {
+					t.Errorf("revert_at = %s, want within 5m", at)
+				}
+			}
+		})
+	}
+}
+
+func TestSetLogLevel_Allowlist(t *testing.T) {
+	ca := certstest.NewCA(t, "test-ca")
+	srvCert := ca.Server(t)
+
+	tlsCfg, err := certs.ServerConfig(discardLogger(), config.TLSConfig{
+		CertFile:     srvCert.CertFile,
+		KeyFile:      srvCert.KeyFile,
+		MinVersion:   "1.2",
+		ClientCAFile: ca.CertFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		client *certstest.Pair
+		want   codes.Code
+	}{
+		{name: "operator", client: ca.Client(t, "ops"), want: codes.OK},
+		{name: "other client", client: ca.Client(t, "gateway"), want: codes.PermissionDenied},
+		{name: "no client cert", want: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var level slog.LevelVar
+			levels := loglevel.NewController(discardLogger(), &level)
+
+			srv := grpc.NewServer(
+				grpc.Creds(credentials.NewTLS(tlsCfg)),
+				grpc.ChainUnaryInterceptor(
+					interceptors.UnaryClientAllowlist([]string{SetLogLevelMethod}, []string{"ops"}),
+				),
+			)
+
+			clientCfg := &tls.Config{ServerName: "localhost", RootCAs: ca.Pool(), MinVersion: tls.VersionTLS12}
+			if tt.client != nil {
+				clientCfg.Certificates = []tls.Certificate{tt.client.TLS}
+			}
+
+			conn := serve(t, srv, levels, credentials.NewTLS(clientCfg))
+
+			_, err := setLogLevel(conn, map[string]any{"level": "debug", "ttl": "1m"})
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("SetLogLevel() code = %v, want %v (%v)", got, tt.want, err)
+			}
+
+			if changed := level.Level() == slog.LevelDebug; changed != (tt.want == codes.OK) {
+				t.Errorf("level = %s after a %v call", level.Level(), tt.want)
+			}
+		})
+	}
+}
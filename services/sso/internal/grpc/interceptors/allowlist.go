@@ -0,0 +1,70 @@
+package interceptors
+
+import (
+	"context"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientAllowlist rejects calls to the given privileged methods unless
+// the caller presented a verified client certificate whose common name or
+// DNS SAN is in clients. Other methods are not affected.
+func UnaryClientAllowlist(methods, clients []string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if slices.Contains(methods, info.FullMethod) && !clientAllowed(ctx, clients) {
+			return nil, status.Error(codes.PermissionDenied, "client certificate is not allowed to call this method")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamClientAllowlist is UnaryClientAllowlist for streaming methods.
+func StreamClientAllowlist(methods, clients []string) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if slices.Contains(methods, info.FullMethod) && !clientAllowed(ss.Context(), clients) {
+			return status.Error(codes.PermissionDenied, "client certificate is not allowed to call this method")
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func clientAllowed(ctx context.Context, clients []string) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return false
+	}
+
+	leaf := tlsInfo.State.VerifiedChains[0][0]
+	if slices.Contains(clients, leaf.Subject.CommonName) {
+		return true
+	}
+	for _, name := range leaf.DNSNames {
+		if slices.Contains(clients, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,130 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"sso/internal/config"
+	"sso/internal/lib/certs"
+	"sso/internal/lib/certs/certstest"
+)
+
+const (
+	checkMethod = "/grpc.health.v1.Health/Check"
+	watchMethod = "/grpc.health.v1.Health/Watch"
+)
+
+// startTLSServer serves the gRPC health service over mutual TLS with Check
+// and Watch restricted to the "gateway" client.
+func startTLSServer(t *testing.T, ca *certstest.CA) *bufconn.Listener {
+	t.Helper()
+
+	srvCert := ca.Server(t)
+
+	tlsCfg, err := certs.ServerConfig(slog.New(slog.NewTextHandler(io.Discard, nil)), config.TLSConfig{
+		CertFile:     srvCert.CertFile,
+		KeyFile:      srvCert.KeyFile,
+		MinVersion:   "1.2",
+		ClientCAFile: ca.CertFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	methods := []string{checkMethod, watchMethod}
+	clients := []string{"gateway"}
+
+	srv := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(tlsCfg)),
+		grpc.ChainUnaryInterceptor(UnaryClientAllowlist(methods, clients)),
+		grpc.ChainStreamInterceptor(StreamClientAllowlist(methods, clients)),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return lis
+}
+
+func dialHealth(t *testing.T, lis *bufconn.Listener, tlsCfg *tls.Config) healthpb.HealthClient {
+	t.Helper()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestClientAllowlist(t *testing.T) {
+	ca := certstest.NewCA(t, "test-ca")
+	lis := startTLSServer(t, ca)
+
+	tests := []struct {
+		name   string
+		client *certstest.Pair
+		want   codes.Code
+	}{
+		{name: "allowed client", client: ca.Client(t, "gateway"), want: codes.OK},
+		{name: "other client", client: ca.Client(t, "intruder"), want: codes.PermissionDenied},
+		{name: "no client cert", want: codes.PermissionDenied},
+		{
+			name:   "allowed name from another CA",
+			client: certstest.NewCA(t, "other-ca").Client(t, "gateway"),
+			want:   codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsCfg := &tls.Config{ServerName: "localhost", RootCAs: ca.Pool(), MinVersion: tls.VersionTLS12}
+			if tt.client != nil {
+				tlsCfg.Certificates = []tls.Certificate{tt.client.TLS}
+			}
+
+			client := dialHealth(t, lis, tlsCfg)
+			ctx := context.Background()
+
+			_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("Check() code = %v, want %v (%v)", got, tt.want, err)
+			}
+
+			stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("Watch() code = %v, want %v (%v)", got, tt.want, err)
+			}
+
+			// Methods outside the allowlist stay open to any verified TLS client.
+			if tt.want == codes.PermissionDenied {
+				if _, err := client.List(ctx, &healthpb.HealthListRequest{}); err != nil {
+					t.Errorf("List() error = %v, want nil", err)
+				}
+			}
+		})
+	}
+}
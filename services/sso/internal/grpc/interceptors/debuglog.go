@@ -0,0 +1,76 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"sso/internal/lib/logger/pkglevel"
+)
+
+const (
+	// DebugLogHeader turns on debug logging for a single call when set to
+	// "true" or "1".
+	DebugLogHeader = "x-debug-log"
+	// DebugTokenHeader carries the admin token that authorizes
+	// DebugLogHeader for callers without a privileged client certificate.
+	DebugTokenHeader = "x-debug-token"
+)
+
+// UnaryDebugLog honours DebugLogHeader only for trusted callers: those that
+// send token in DebugTokenHeader or present a verified client certificate
+// whose common name or DNS SAN is in clients. An empty token or clients
+// list disables that way in.
+func UnaryDebugLog(token string, clients []string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		return handler(withDebugLog(ctx, token, clients), req)
+	}
+}
+
+func StreamDebugLog(token string, clients []string) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		_ *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx := withDebugLog(ss.Context(), token, clients)
+
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func withDebugLog(ctx context.Context, token string, clients []string) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	vals := md.Get(DebugLogHeader)
+	if len(vals) == 0 || (vals[0] != "true" && vals[0] != "1") {
+		return ctx
+	}
+
+	if debugTokenValid(md, token) || (len(clients) > 0 && clientAllowed(ctx, clients)) {
+		return pkglevel.WithDebug(ctx)
+	}
+
+	return ctx
+}
+
+func debugTokenValid(md metadata.MD, token string) bool {
+	if token == "" {
+		return false
+	}
+
+	vals := md.Get(DebugTokenHeader)
+
+	return len(vals) > 0 && subtle.ConstantTimeCompare([]byte(vals[0]), []byte(token)) == 1
+}
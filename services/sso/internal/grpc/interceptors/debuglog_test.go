@@ -0,0 +1,113 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log/slog"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"sso/internal/lib/certs/certstest"
+	"sso/internal/lib/logger/pkglevel"
+)
+
+// withVerifiedClient returns ctx as seen by a server that verified a client
+// certificate with the given common name.
+func withVerifiedClient(t *testing.T, ctx context.Context, name string) context.Context {
+	t.Helper()
+
+	pair := certstest.NewCA(t, "test-ca").Client(t, name)
+
+	cert, err := x509.ParseCertificate(pair.TLS.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	}})
+}
+
+func TestDebugLog(t *testing.T) {
+	const token = "s3cret"
+
+	levels := pkglevel.NewHandler(
+		slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}), slog.LevelInfo, nil,
+	)
+
+	tests := []struct {
+		name    string
+		token   string
+		clients []string
+		md      []string
+		cert    string
+		want    bool
+	}{
+		{name: "no header", token: token, md: []string{DebugTokenHeader, token}},
+		{name: "header without token", token: token, md: []string{DebugLogHeader, "true"}},
+		{name: "wrong token", token: token, md: []string{DebugLogHeader, "true", DebugTokenHeader, "guess"}},
+		{name: "token", token: token, md: []string{DebugLogHeader, "true", DebugTokenHeader, token}, want: true},
+		{name: "header off", token: token, md: []string{DebugLogHeader, "false", DebugTokenHeader, token}},
+		{name: "empty token never matches", md: []string{DebugLogHeader, "1", DebugTokenHeader, ""}},
+		{
+			name: "privileged client", clients: []string{"gateway"}, cert: "gateway",
+			md: []string{DebugLogHeader, "1"}, want: true,
+		},
+		{name: "other client", clients: []string{"gateway"}, cert: "intruder", md: []string{DebugLogHeader, "1"}},
+	}
+
+	// calls run the interceptor under test and report whether the handler
+	// saw debug logging enabled.
+	calls := map[string]func(ctx context.Context, token string, clients []string) (bool, error){
+		"unary": func(ctx context.Context, token string, clients []string) (bool, error) {
+			var got bool
+			_, err := UnaryDebugLog(token, clients)(ctx, nil, &grpc.UnaryServerInfo{},
+				func(ctx context.Context, _ any) (any, error) {
+					got = levels.Enabled(ctx, slog.LevelDebug)
+
+					return nil, nil
+				},
+			)
+
+			return got, err
+		},
+		"stream": func(ctx context.Context, token string, clients []string) (bool, error) {
+			var got bool
+			err := StreamDebugLog(token, clients)(nil, &wrappedStream{ctx: ctx}, &grpc.StreamServerInfo{},
+				func(_ any, ss grpc.ServerStream) error {
+					got = levels.Enabled(ss.Context(), slog.LevelDebug)
+
+					return nil
+				},
+			)
+
+			return got, err
+		},
+	}
+
+	for _, tt := range tests {
+		for kind, call := range calls {
+			t.Run(tt.name+"/"+kind, func(t *testing.T) {
+				ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(tt.md...))
+				if tt.cert != "" {
+					ctx = withVerifiedClient(t, ctx, tt.cert)
+				}
+
+				got, err := call(ctx, tt.token, tt.clients)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got != tt.want {
+					t.Errorf("debug enabled = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
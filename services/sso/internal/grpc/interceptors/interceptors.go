@@ -0,0 +1,38 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc"
+)
+
+// ServerOptions returns the interceptor chain every sso gRPC server is built
+// with: request ID first, then metrics and access logging, then panic
+// recovery, so that recovered panics are counted and logged as codes.Internal.
+func ServerOptions(log *slog.Logger) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			UnaryRequestID(),
+			UnaryMetrics(),
+			UnaryLogging(log),
+			UnaryRecovery(log),
+		),
+		grpc.ChainStreamInterceptor(
+			StreamRequestID(),
+			StreamMetrics(),
+			StreamLogging(log),
+			StreamRecovery(log),
+		),
+	}
+}
+
+// wrappedStream overrides the context of a grpc.ServerStream.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
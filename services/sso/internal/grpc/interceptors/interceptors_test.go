@@ -0,0 +1,181 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"sso/internal/lib/requestid"
+)
+
+// Service names that make fakeHealth misbehave.
+const (
+	servicePanic    = "panic"
+	serviceNotFound = "notfound"
+)
+
+// fakeHealth is a health service whose outcome is picked by the requested
+// service name. It records the request ID its handlers saw.
+type fakeHealth struct {
+	healthpb.UnimplementedHealthServer
+
+	mu        sync.Mutex
+	requestID string
+}
+
+func (f *fakeHealth) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	f.seen(ctx)
+
+	switch req.GetService() {
+	case servicePanic:
+		panic("boom")
+	case serviceNotFound:
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (f *fakeHealth) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	f.seen(stream.Context())
+
+	switch req.GetService() {
+	case servicePanic:
+		panic("boom")
+	case serviceNotFound:
+		return status.Error(codes.NotFound, "unknown service")
+	}
+
+	return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+func (f *fakeHealth) seen(ctx context.Context) {
+	id, _ := requestid.FromContext(ctx)
+
+	f.mu.Lock()
+	f.requestID = id
+	f.mu.Unlock()
+}
+
+func (f *fakeHealth) lastRequestID() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.requestID
+}
+
+// logBuffer collects JSON log lines written by server goroutines.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+// records returns the decoded lines whose msg is msg.
+func (b *logBuffer) records(t *testing.T, msg string) []map[string]any {
+	t.Helper()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var res []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		if m["msg"] == msg {
+			res = append(res, m)
+		}
+	}
+
+	return res
+}
+
+// wait returns the records with msg once there are n of them. Server-side
+// logging can finish after the client already has its response.
+func (b *logBuffer) wait(t *testing.T, msg string, n int) []map[string]any {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		recs := b.records(t, msg)
+		if len(recs) >= n || time.Now().After(deadline) {
+			return recs
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// startServer serves fakeHealth over bufconn with the standard interceptor
+// chain and returns a client for it.
+func startServer(t *testing.T) (healthpb.HealthClient, *fakeHealth, *logBuffer) {
+	t.Helper()
+
+	logs := &logBuffer{}
+	log := slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	svc := &fakeHealth{}
+	srv := grpc.NewServer(ServerOptions(log)...)
+	healthpb.RegisterHealthServer(srv, svc)
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return healthpb.NewHealthClient(conn), svc, logs
+}
+
+// watchOnce opens a Watch stream and reads until the server ends it.
+func watchOnce(ctx context.Context, client healthpb.HealthClient, service string, opts ...grpc.CallOption) error {
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: service}, opts...)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestLogging(t *testing.T) {
+	tests := []struct {
+		name      string
+		service   string
+		stream    bool
+		wantCode  codes.Code
+		wantLevel slog.Level
+	}{
+		{name: "unary ok", wantCode: codes.OK, wantLevel: slog.LevelInfo},
+		{name: "unary client error", service: serviceNotFound, wantCode: codes.NotFound, wantLevel: slog.LevelWarn},
+		{name: "unary server error", service: servicePanic, wantCode: codes.Internal, wantLevel: slog.LevelError},
+		{name: "stream ok", stream: true, wantCode: codes.OK, wantLevel: slog.LevelInfo},
+		{name: "stream client error", service: serviceNotFound, stream: true, wantCode: codes.NotFound, wantLevel: slog.LevelWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, logs := startServer(t)
+
+			method := checkMethod
+			if tt.stream {
+				method = watchMethod
+				_ = watchOnce(context.Background(), client, tt.service)
+			} else {
+				_, _ = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: tt.service})
+			}
+
+			recs := logs.wait(t, "grpc call", 1)
+			if len(recs) != 1 {
+				t.Fatalf("got %d access log records, want 1", len(recs))
+			}
+			rec := recs[0]
+
+			if rec["level"] != tt.wantLevel.String() {
+				t.Errorf("level = %v, want %s", rec["level"], tt.wantLevel)
+			}
+			if rec["method"] != method {
+				t.Errorf("method = %v, want %s", rec["method"], method)
+			}
+			if rec["code"] != tt.wantCode.String() {
+				t.Errorf("code = %v, want %s", rec["code"], tt.wantCode)
+			}
+			if d, ok := rec["duration"].(float64); !ok || d <= 0 {
+				t.Errorf("duration = %v, want a positive number", rec["duration"])
+			}
+			if rec["peer"] != "bufconn" {
+				t.Errorf("peer = %v, want bufconn", rec["peer"])
+			}
+			if _, hasErr := rec["error"]; hasErr != (tt.wantCode != codes.OK) {
+				t.Errorf("error attr present = %v for code %s", hasErr, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestLevelFor(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want slog.Level
+	}{
+		{codes.OK, slog.LevelInfo},
+		{codes.Canceled, slog.LevelWarn},
+		{codes.InvalidArgument, slog.LevelWarn},
+		{codes.NotFound, slog.LevelWarn},
+		{codes.PermissionDenied, slog.LevelWarn},
+		{codes.Unauthenticated, slog.LevelWarn},
+		{codes.DeadlineExceeded, slog.LevelWarn},
+		{codes.Unknown, slog.LevelError},
+		{codes.Internal, slog.LevelError},
+		{codes.DataLoss, slog.LevelError},
+		{codes.Unimplemented, slog.LevelError},
+		{codes.Unavailable, slog.LevelError},
+	}
+
+	for _, tt := range tests {
+		if got := levelFor(tt.code); got != tt.want {
+			t.Errorf("levelFor(%s) = %s, want %s", tt.code, got, tt.want)
+		}
+	}
+}
+
+// Durations are logged as nanoseconds by the JSON handler; make sure a
+// slow call is not reported as zero.
+func TestLogCall_Duration(t *testing.T) {
+	logs := &logBuffer{}
+	log := slog.New(slog.NewJSONHandler(logs, nil))
+
+	logCall(context.Background(), log, checkMethod, nil, 1500*time.Millisecond)
+
+	recs := logs.records(t, "grpc call")
+	if len(recs) != 1 || recs[0]["duration"] != float64(1500*time.Millisecond) {
+		t.Errorf("records = %v, want duration 1.5s in ns", recs)
+	}
+	if _, ok := recs[0]["peer"]; ok {
+		t.Error("peer logged without a peer in the context")
+	}
+}
@@ -0,0 +1,52 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"sso/internal/lib/metrics"
+)
+
+func UnaryMetrics() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		observeCall(info.FullMethod, err, start)
+
+		return resp, err
+	}
+}
+
+func StreamMetrics() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		start := time.Now()
+
+		err := handler(srv, ss)
+
+		observeCall(info.FullMethod, err, start)
+
+		return err
+	}
+}
+
+func observeCall(method string, err error, start time.Time) {
+	code := status.Code(err).String()
+
+	metrics.GRPCHandled.WithLabelValues(method, code).Inc()
+	metrics.GRPCHandlingSeconds.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,70 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"sso/internal/lib/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		service  string
+		wantCode codes.Code
+	}{
+		{name: "unary ok", method: checkMethod, wantCode: codes.OK},
+		{name: "unary error", method: checkMethod, service: serviceNotFound, wantCode: codes.NotFound},
+		{name: "unary panic", method: checkMethod, service: servicePanic, wantCode: codes.Internal},
+		{name: "stream ok", method: watchMethod, wantCode: codes.OK},
+		{name: "stream error", method: watchMethod, service: serviceNotFound, wantCode: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, logs := startServer(t)
+
+			handled := metrics.GRPCHandled.WithLabelValues(tt.method, tt.wantCode.String())
+			before := testutil.ToFloat64(handled)
+			observedBefore := observed(t, tt.method, tt.wantCode)
+
+			if tt.method == watchMethod {
+				_ = watchOnce(context.Background(), client, tt.service)
+			} else {
+				_, _ = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: tt.service})
+			}
+
+			// The access log is written after the metrics, so once it is
+			// there the counter has been updated.
+			logs.wait(t, "grpc call", 1)
+
+			if got := testutil.ToFloat64(handled) - before; got != 1 {
+				t.Errorf("sso_grpc_server_handled_total{method=%q,code=%q} grew by %v, want 1",
+					tt.method, tt.wantCode, got)
+			}
+			if got := observed(t, tt.method, tt.wantCode) - observedBefore; got != 1 {
+				t.Errorf("sso_grpc_server_handling_seconds count grew by %d, want 1", got)
+			}
+		})
+	}
+}
+
+// observed returns the sample count of the handling time histogram.
+func observed(t *testing.T, method string, code codes.Code) uint64 {
+	t.Helper()
+
+	var m dto.Metric
+	h := metrics.GRPCHandlingSeconds.WithLabelValues(method, code.String()).(prometheus.Histogram)
+	if err := h.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+
+	return m.GetHistogram().GetSampleCount()
+}
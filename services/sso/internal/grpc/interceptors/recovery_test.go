@@ -0,0 +1,69 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		call   func(client healthpb.HealthClient) error
+	}{
+		{
+			name:   "unary",
+			method: checkMethod,
+			call: func(client healthpb.HealthClient) error {
+				_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: servicePanic})
+
+				return err
+			},
+		},
+		{
+			name:   "stream",
+			method: watchMethod,
+			call: func(client healthpb.HealthClient) error {
+				return watchOnce(context.Background(), client, servicePanic)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, logs := startServer(t)
+
+			err := tt.call(client)
+			if got := status.Code(err); got != codes.Internal {
+				t.Fatalf("code = %v, want Internal (%v)", got, err)
+			}
+			if strings.Contains(err.Error(), "boom") {
+				t.Errorf("panic value leaked to the client: %v", err)
+			}
+
+			recs := logs.wait(t, "recovered from panic", 1)
+			if len(recs) != 1 {
+				t.Fatalf("got %d panic records, want 1", len(recs))
+			}
+
+			rec := recs[0]
+			if rec["level"] != "ERROR" || rec["method"] != tt.method || rec["panic"] != "boom" {
+				t.Errorf("panic record = %v", rec)
+			}
+			if stack, _ := rec["stack"].(string); !strings.Contains(stack, "fakeHealth") {
+				t.Errorf("stack does not point at the handler: %q", stack)
+			}
+
+			// The access log sees the recovered error, not the panic.
+			calls := logs.wait(t, "grpc call", 1)
+			if len(calls) != 1 || calls[0]["code"] != codes.Internal.String() {
+				t.Errorf("access log = %v, want one Internal call", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"sso/internal/lib/requestid"
+)
+
+// RequestIDHeader is the metadata key the request ID is read from and
+// echoed back in.
+const RequestIDHeader = "x-request-id"
+
+const maxRequestIDLen = 128
+
+func UnaryRequestID() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		return handler(withRequestID(ctx), req)
+	}
+}
+
+func StreamRequestID() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		_ *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+	}
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	id := incomingRequestID(ctx)
+	if id == "" {
+		id = requestid.New()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+
+	return requestid.NewContext(ctx, id)
+}
+
+func incomingRequestID(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	vals := md.Get(RequestIDHeader)
+	if len(vals) == 0 || len(vals[0]) > maxRequestIDLen {
+		return ""
+	}
+
+	return vals[0]
+}
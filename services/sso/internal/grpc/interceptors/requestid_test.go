@@ -0,0 +1,70 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestID(t *testing.T) {
+	client, svc, _ := startServer(t)
+
+	tests := []struct {
+		name     string
+		incoming string
+		// reused reports whether the incoming ID must be kept.
+		reused bool
+	}{
+		{name: "generated when missing"},
+		{name: "incoming reused", incoming: "req-123", reused: true},
+		{name: "max length reused", incoming: strings.Repeat("a", maxRequestIDLen), reused: true},
+		{name: "too long replaced", incoming: strings.Repeat("a", maxRequestIDLen+1)},
+	}
+
+	calls := map[string]func(ctx context.Context, header *metadata.MD) error{
+		"unary": func(ctx context.Context, header *metadata.MD) error {
+			_, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(header))
+
+			return err
+		},
+		"stream": func(ctx context.Context, header *metadata.MD) error {
+			return watchOnce(ctx, client, "", grpc.Header(header))
+		},
+	}
+
+	for _, tt := range tests {
+		for kind, call := range calls {
+			t.Run(tt.name+"/"+kind, func(t *testing.T) {
+				ctx := context.Background()
+				if tt.incoming != "" {
+					ctx = metadata.AppendToOutgoingContext(ctx, RequestIDHeader, tt.incoming)
+				}
+
+				var header metadata.MD
+				if err := call(ctx, &header); err != nil {
+					t.Fatal(err)
+				}
+
+				echoed := header.Get(RequestIDHeader)
+				if len(echoed) != 1 || echoed[0] == "" {
+					t.Fatalf("response %s = %v, want one ID", RequestIDHeader, echoed)
+				}
+
+				id := echoed[0]
+				if tt.reused && id != tt.incoming {
+					t.Errorf("ID = %q, want incoming %q", id, tt.incoming)
+				}
+				if !tt.reused && (id == tt.incoming || len(id) > maxRequestIDLen) {
+					t.Errorf("ID = %q, want a freshly generated one", id)
+				}
+				if got := svc.lastRequestID(); got != id {
+					t.Errorf("handler saw ID %q, header has %q", got, id)
+				}
+			})
+		}
+	}
+}
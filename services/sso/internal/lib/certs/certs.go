@@ -0,0 +1,170 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"sso/internal/config"
+)
+
+// statInterval throttles how often handshakes stat the files for changes.
+// It is a variable so tests can turn the throttling off.
+var statInterval = time.Second
+
+// ServerConfig builds the gRPC server TLS config. The certificate, key and
+// client CA are re-read whenever their files change, so rotated
+// certificates are picked up without a restart.
+func ServerConfig(log *slog.Logger, cfg config.TLSConfig) (*tls.Config, error) {
+	const op = "certs.ServerConfig"
+
+	minVersion, err := parseVersion(cfg.MinVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r := &reloader{
+		log:   log.With(slog.String("op", op)),
+		files: []string{cfg.CertFile, cfg.KeyFile, cfg.ClientCAFile},
+		cfg:   cfg,
+	}
+	if err := r.load(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	clientAuth := tls.NoClientCert
+	if cfg.ClientCAFile != "" {
+		clientAuth = tls.VerifyClientCertIfGiven
+		if cfg.RequireClientCert {
+			clientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return &tls.Config{
+		MinVersion: minVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+
+			return &tls.Config{
+				MinVersion:   minVersion,
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    pool,
+				ClientAuth:   clientAuth,
+				NextProtos:   []string{"h2"},
+			}, nil
+		},
+	}, nil
+}
+
+func parseVersion(v string) (uint16, error) {
+	switch v {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q (want 1.2 or 1.3)", v)
+	}
+}
+
+type reloader struct {
+	log   *slog.Logger
+	files []string
+	cfg   config.TLSConfig
+
+	mu       sync.Mutex
+	checked  time.Time
+	modTimes []time.Time
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+}
+
+// current returns the loaded certificate and client CA pool, reloading
+// them first if any of the files changed since the last check.
+func (r *reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checked) >= statInterval {
+		r.checked = time.Now()
+
+		if mt := modTimes(r.files); !equalTimes(mt, r.modTimes) {
+			if err := r.loadLocked(); err != nil {
+				r.log.Error("failed to reload certificates, keeping the old ones", slog.String("error", err.Error()))
+			} else {
+				r.log.Info("reloaded certificates")
+			}
+		}
+	}
+
+	return r.cert, r.pool
+}
+
+func (r *reloader) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.loadLocked()
+}
+
+func (r *reloader) loadLocked() error {
+	// Record mod times before reading so a write racing with the read is
+	// seen on the next check.
+	mt := modTimes(r.files)
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("read client CA: %w", err)
+		}
+
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("client CA file contains no certificates")
+		}
+	}
+
+	r.cert = &cert
+	r.pool = pool
+	r.modTimes = mt
+
+	return nil
+}
+
+func modTimes(files []string) []time.Time {
+	res := make([]time.Time, len(files))
+	for i, f := range files {
+		if f == "" {
+			continue
+		}
+		if fi, err := os.Stat(f); err == nil {
+			res[i] = fi.ModTime()
+		}
+	}
+
+	return res
+}
+
+func equalTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
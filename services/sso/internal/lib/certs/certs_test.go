@@ -0,0 +1,155 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"sso/internal/config"
+	"sso/internal/lib/certs/certstest"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func servedCert(t *testing.T, cfg *tls.Config) []byte {
+	t.Helper()
+
+	c, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c.Certificates[0].Certificate[0]
+}
+
+// replaceFile overwrites dst with src and moves its mod time forward, so
+// the change is seen even on filesystems with coarse timestamps.
+func replaceFile(t *testing.T, dst string, b []byte, at time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(dst, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(dst, at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestServerConfig_Reload(t *testing.T) {
+	old := statInterval
+	statInterval = 0
+	t.Cleanup(func() { statInterval = old })
+
+	ca := certstest.NewCA(t, "test-ca")
+	first := ca.Server(t)
+
+	cfg, err := ServerConfig(discard, config.TLSConfig{
+		CertFile:     first.CertFile,
+		KeyFile:      first.KeyFile,
+		MinVersion:   "1.3",
+		ClientCAFile: ca.CertFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(servedCert(t, cfg), first.TLS.Certificate[0]) {
+		t.Fatal("initial certificate is not served")
+	}
+
+	second := ca.Server(t)
+	at := time.Now().Add(time.Minute)
+	replaceFile(t, first.CertFile, readFile(t, second.CertFile), at)
+	replaceFile(t, first.KeyFile, readFile(t, second.KeyFile), at)
+
+	if !bytes.Equal(servedCert(t, cfg), second.TLS.Certificate[0]) {
+		t.Fatal("rotated certificate is not served")
+	}
+
+	replaceFile(t, first.CertFile, []byte("garbage"), at.Add(time.Minute))
+
+	if !bytes.Equal(servedCert(t, cfg), second.TLS.Certificate[0]) {
+		t.Fatal("broken certificate replaced the loaded one")
+	}
+}
+
+func TestServerConfig_ClientAuth(t *testing.T) {
+	ca := certstest.NewCA(t, "test-ca")
+	srv := ca.Server(t)
+
+	tests := []struct {
+		name string
+		cfg  config.TLSConfig
+		want tls.ClientAuthType
+	}{
+		{name: "tls only", cfg: config.TLSConfig{}, want: tls.NoClientCert},
+		{name: "optional client cert", cfg: config.TLSConfig{ClientCAFile: ca.CertFile}, want: tls.VerifyClientCertIfGiven},
+		{
+			name: "required client cert",
+			cfg:  config.TLSConfig{ClientCAFile: ca.CertFile, RequireClientCert: true},
+			want: tls.RequireAndVerifyClientCert,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.CertFile, tt.cfg.KeyFile, tt.cfg.MinVersion = srv.CertFile, srv.KeyFile, "1.2"
+
+			cfg, err := ServerConfig(discard, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.ClientAuth != tt.want {
+				t.Errorf("ClientAuth = %v, want %v", c.ClientAuth, tt.want)
+			}
+			if c.MinVersion != tls.VersionTLS12 {
+				t.Errorf("MinVersion = %x, want TLS 1.2", c.MinVersion)
+			}
+		})
+	}
+}
+
+func TestServerConfig_Errors(t *testing.T) {
+	ca := certstest.NewCA(t, "test-ca")
+	srv := ca.Server(t)
+
+	tests := []struct {
+		name string
+		cfg  config.TLSConfig
+	}{
+		{name: "bad version", cfg: config.TLSConfig{CertFile: srv.CertFile, KeyFile: srv.KeyFile, MinVersion: "1.1"}},
+		{name: "missing key", cfg: config.TLSConfig{CertFile: srv.CertFile, KeyFile: srv.CertFile + ".nope", MinVersion: "1.2"}},
+		{
+			name: "CA without certificates",
+			cfg:  config.TLSConfig{CertFile: srv.CertFile, KeyFile: srv.KeyFile, MinVersion: "1.2", ClientCAFile: srv.KeyFile},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ServerConfig(discard, tt.cfg); err == nil {
+				t.Fatal("ServerConfig() error = nil, want error")
+			}
+		})
+	}
+}
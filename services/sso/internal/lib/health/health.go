@@ -0,0 +1,189 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// AuthService is the fully-qualified name of the Auth gRPC service.
+const AuthService = "auth.Auth"
+
+const checkTimeout = 5 * time.Second
+
+// Checker reports whether a dependency is usable. Storage registers one for
+// connectivity and one for pending migrations.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Health runs the registered checks and mirrors their result into the
+// grpc.health.v1 server and the /healthz and /readyz HTTP handlers.
+type Health struct {
+	log    *slog.Logger
+	server *grpchealth.Server
+
+	mu       sync.RWMutex
+	checks   map[string]Checker
+	failures map[string]string
+
+	ready    atomic.Bool
+	draining atomic.Bool
+}
+
+func New(log *slog.Logger) *Health {
+	s := grpchealth.NewServer()
+	s.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	s.SetServingStatus(AuthService, healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return &Health{
+		log:      log,
+		server:   s,
+		checks:   make(map[string]Checker),
+		failures: make(map[string]string),
+	}
+}
+
+func (h *Health) AddCheck(name string, c Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks[name] = c
+}
+
+// Register registers the grpc.health.v1 service on s.
+func (h *Health) Register(s grpc.ServiceRegistrar) {
+	healthpb.RegisterHealthServer(s, h.server)
+}
+
+// Run checks dependencies every interval until ctx is done.
+func (h *Health) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.checkAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain marks the service as not ready so probes and load balancers stop
+// sending traffic before the servers are stopped.
+func (h *Health) Drain() {
+	if h.draining.Swap(true) {
+		return
+	}
+
+	h.log.Info("draining: reporting not ready")
+
+	// Shutdown sets every service to NOT_SERVING and makes the health
+	// server ignore later updates, so a check round still in flight
+	// cannot flip it back to SERVING.
+	h.server.Shutdown()
+}
+
+func (h *Health) checkAll(ctx context.Context) {
+	h.mu.RLock()
+	checks := make(map[string]Checker, len(h.checks))
+	for name, c := range h.checks {
+		checks[name] = c
+	}
+	h.mu.RUnlock()
+
+	failures := make(map[string]string)
+
+	for name, c := range checks {
+		cctx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := c.Check(cctx)
+		cancel()
+
+		if err != nil {
+			failures[name] = err.Error()
+		}
+	}
+
+	h.mu.Lock()
+	h.failures = failures
+	h.mu.Unlock()
+
+	ok := len(failures) == 0
+	if ok != h.ready.Load() {
+		if ok {
+			h.log.Info("health checks passed")
+		} else {
+			h.log.Warn("health checks failed", slog.Any("failures", failures))
+		}
+	}
+
+	h.ready.Store(ok)
+
+	if !h.draining.Load() {
+		h.setServing(ok)
+	}
+}
+
+func (h *Health) setServing(ok bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if ok {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+
+	h.server.SetServingStatus("", status)
+	h.server.SetServingStatus(AuthService, status)
+}
+
+// LiveHandler serves /healthz: the process is up and able to answer.
+func (h *Health) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeStatus(w, http.StatusOK, "ok", nil)
+	})
+}
+
+// ReadyHandler serves /readyz: all checks pass and the service is not draining.
+func (h *Health) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		switch {
+		case h.draining.Load():
+			writeStatus(w, http.StatusServiceUnavailable, "draining", nil)
+		case !h.ready.Load():
+			h.mu.RLock()
+			failures := h.failures
+			h.mu.RUnlock()
+
+			writeStatus(w, http.StatusServiceUnavailable, "not ready", failures)
+		default:
+			writeStatus(w, http.StatusOK, "ok", nil)
+		}
+	})
+}
+
+type statusResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func writeStatus(w http.ResponseWriter, code int, status string, failures map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(statusResponse{Status: status, Checks: failures})
+}
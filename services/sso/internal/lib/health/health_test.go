@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func newTestHealth() *Health {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func servingStatus(t *testing.T, h *Health) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	resp, err := h.server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: AuthService})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.GetStatus()
+}
+
+func readyCode(h *Health) int {
+	rec := httptest.NewRecorder()
+	h.ReadyHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	return rec.Code
+}
+
+func TestHealth_Checks(t *testing.T) {
+	h := newTestHealth()
+
+	var checkErr error
+	h.AddCheck("storage", CheckerFunc(func(context.Context) error { return checkErr }))
+
+	h.checkAll(context.Background())
+	if got := servingStatus(t, h); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("status = %v, want SERVING", got)
+	}
+	if got := readyCode(h); got != http.StatusOK {
+		t.Errorf("readyz = %d, want 200", got)
+	}
+
+	checkErr = errors.New("connection refused")
+	h.checkAll(context.Background())
+	if got := servingStatus(t, h); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("status = %v, want NOT_SERVING", got)
+	}
+	if got := readyCode(h); got != http.StatusServiceUnavailable {
+		t.Errorf("readyz = %d, want 503", got)
+	}
+}
+
+func TestHealth_DrainWins(t *testing.T) {
+	h := newTestHealth()
+	h.checkAll(context.Background())
+
+	h.Drain()
+
+	// A check round finishing after Drain must not report SERVING again.
+	h.ready.Store(false)
+	h.checkAll(context.Background())
+
+	if got := servingStatus(t, h); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("status after drain = %v, want NOT_SERVING", got)
+	}
+	if got := readyCode(h); got != http.StatusServiceUnavailable {
+		t.Errorf("readyz after drain = %d, want 503", got)
+	}
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"sso/internal/config"
+	"sso/internal/lib/logger/pkglevel"
+	"sso/internal/lib/logger/slogctx"
+	"sso/internal/lib/logger/slogpretty"
+	"sso/internal/lib/logger/slogredact"
+)
+
+// allLevels lets the format handlers accept everything; level filtering
+// happens once, in pkglevel.
+const allLevels = slog.Level(math.MinInt)
+
+// New builds the sso logger from cfg. level is the base level and can be
+// changed at runtime. The returned closer flushes and closes file outputs.
+func New(cfg *config.Config, level slog.Leveler) (*slog.Logger, io.Closer, error) {
+	const op = "logger.New"
+
+	out, closer, err := outputs(cfg.Log.Outputs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var h slog.Handler
+
+	opts := &slog.HandlerOptions{Level: allLevels}
+
+	switch format := cfg.LogFormat(); format {
+	case config.LogFormatPretty:
+		h = slogpretty.PrettyHandlerOptions{SlogOpts: opts}.NewPrettyHandler(out)
+	case config.LogFormatJSON:
+		h = slog.NewJSONHandler(out, opts)
+	case config.LogFormatText:
+		h = slog.NewTextHandler(out, opts)
+	default:
+		_ = closer.Close()
+
+		return nil, nil, fmt.Errorf("%s: unknown log format %q", op, format)
+	}
+
+	overrides, err := cfg.Log.PackageLevels()
+	if err != nil {
+		_ = closer.Close()
+
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if cfg.Log.Redact.Enabled {
+		h = slogredact.NewHandler(h, cfg.Log.Redact.Keys)
+	}
+
+	// Filter before redacting so dropped records cost nothing.
+	h = pkglevel.NewHandler(h, level, overrides)
+
+	// Carry request-scoped values (request ID, trace IDs) from the context into records.
+	h = slogctx.NewHandler(h)
+
+	return slog.New(h), closer, nil
+}
+
+func outputs(cfgs []config.LogOutput) (io.Writer, io.Closer, error) {
+	if len(cfgs) == 0 {
+		return os.Stdout, multiCloser(nil), nil
+	}
+
+	var (
+		writers []io.Writer
+		closers multiCloser
+	)
+
+	for _, o := range cfgs {
+		switch o.Type {
+		case config.LogOutputStdout:
+			writers = append(writers, os.Stdout)
+		case config.LogOutputStderr:
+			writers = append(writers, os.Stderr)
+		case config.LogOutputFile:
+			f := &lumberjack.Logger{
+				Filename:   o.Path,
+				MaxSize:    o.MaxSizeMB,
+				MaxAge:     int(math.Ceil(o.MaxAge.Hours() / 24)),
+				MaxBackups: o.MaxBackups,
+				Compress:   o.Compress,
+				LocalTime:  true,
+			}
+			writers = append(writers, f)
+			closers = append(closers, f)
+		default:
+			_ = closers.Close()
+
+			return nil, nil, fmt.Errorf("unknown log output %q", o.Type)
+		}
+	}
+
+	if len(writers) == 1 {
+		return writers[0], closers, nil
+	}
+
+	return io.MultiWriter(writers...), closers, nil
+}
+
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var errs []error
+	for _, c := range m {
+		errs = append(errs, c.Close())
+	}
+
+	return errors.Join(errs...)
+}
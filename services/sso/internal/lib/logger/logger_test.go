@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sso/internal/config"
+	"sso/internal/lib/requestid"
+)
+
+func newFileConfig(t *testing.T, format string) (*config.Config, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "logs", "sso.log")
+
+	return &config.Config{
+		Env: config.EnvProd,
+		Log: config.LogConfig{
+			Format:  format,
+			Outputs: []config.LogOutput{{Type: config.LogOutputFile, Path: path}},
+			Redact:  config.RedactConfig{Enabled: true, Keys: []string{"password"}},
+		},
+	}, path
+}
+
+func TestNew_Formats(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{format: config.LogFormatJSON, want: []string{`"msg":"hello"`, `"request_id":"req-1"`, `"password":"[REDACTED]"`}},
+		{format: config.LogFormatText, want: []string{"msg=hello", "request_id=req-1", "password=[REDACTED]"}},
+		{format: config.LogFormatPretty, want: []string{"INFO: hello", `"request_id": "req-1"`, `"password": "[REDACTED]"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			cfg, path := newFileConfig(t, tt.format)
+
+			log, closer, err := New(cfg, cfg.LogLevel())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := requestid.NewContext(context.Background(), "req-1")
+			log.InfoContext(ctx, "hello", "password", "hunter2")
+			log.DebugContext(ctx, "hidden")
+
+			if err := closer.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := string(b)
+
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+			if strings.Contains(out, "hunter2") || strings.Contains(out, "hidden") {
+				t.Errorf("output has a redacted value or a record below the level:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestNew_PackageOverride(t *testing.T) {
+	tests := []struct {
+		name     string
+		packages map[string]string
+		want     bool
+	}{
+		{name: "this package", packages: map[string]string{"sso/internal/lib/logger": "debug"}, want: true},
+		{name: "parent package", packages: map[string]string{"sso/internal/lib": "debug"}, want: true},
+		{name: "other package", packages: map[string]string{"sso/internal/app": "debug"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, path := newFileConfig(t, config.LogFormatJSON)
+			cfg.Log.Packages = tt.packages
+
+			log, closer, err := New(cfg, cfg.LogLevel())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			log.Debug("debug line")
+
+			if err := closer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := os.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+
+			if got := strings.Contains(string(b), "debug line"); got != tt.want {
+				t.Errorf("debug logged = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		log  config.LogConfig
+	}{
+		{name: "unknown format", log: config.LogConfig{Format: "xml"}},
+		{name: "unknown output", log: config.LogConfig{Outputs: []config.LogOutput{{Type: "syslog"}}}},
+		{name: "bad package level", log: config.LogConfig{Packages: map[string]string{"sso": "loud"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Env: config.EnvProd, Log: tt.log}
+
+			if _, _, err := New(cfg, cfg.LogLevel()); err == nil {
+				t.Fatal("New() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestOutputs(t *testing.T) {
+	dir := t.TempDir()
+
+	w, closer, err := outputs([]config.LogOutput{
+		{Type: config.LogOutputFile, Path: filepath.Join(dir, "a.log")},
+		{Type: config.LogOutputFile, Path: filepath.Join(dir, "b.log")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte("line\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	for _, name := range []string{"a.log", "b.log"} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "line\n" {
+			t.Errorf("%s = %q, want the line written once", name, b)
+		}
+	}
+
+	w, closer, err = outputs(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w != os.Stdout {
+		t.Error("no outputs should mean stdout")
+	}
+	if err := closer.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package loglevel
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxTTL caps how long a temporary level stays in effect.
+const MaxTTL = 24 * time.Hour
+
+// Controller changes the level of a running logger, optionally reverting
+// to the configured level after a TTL.
+type Controller struct {
+	log   *slog.Logger
+	level *slog.LevelVar
+
+	mu       sync.Mutex
+	base     slog.Level
+	revertAt time.Time
+	timer    *time.Timer
+	// gen is bumped by every Set, so a revert whose timer fired while a
+	// newer Set held the lock can tell it is stale.
+	gen uint64
+}
+
+func NewController(log *slog.Logger, level *slog.LevelVar) *Controller {
+	return &Controller{
+		log:   log,
+		level: level,
+		base:  level.Level(),
+	}
+}
+
+// Set switches to level. With a positive ttl the level reverts to the
+// configured one afterwards; with zero it stays until the next change.
+func (c *Controller) Set(level slog.Level, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopTimerLocked()
+	c.gen++
+
+	if ttl > 0 {
+		gen := c.gen
+		c.revertAt = time.Now().Add(ttl)
+		c.timer = time.AfterFunc(ttl, func() { c.revert(gen) })
+	} else {
+		c.base = level
+	}
+
+	c.level.Set(level)
+
+	c.log.Warn("log level changed",
+		slog.String("level", level.String()),
+		slog.Duration("ttl", ttl),
+	)
+}
+
+// SetBase sets the configured level, e.g. after a config reload. A
+// temporary level still in effect keeps running until its TTL expires.
+func (c *Controller) SetBase(level slog.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.base = level
+	if c.timer == nil {
+		c.level.Set(level)
+	}
+}
+
+// Get returns the current level and, for a temporary level, when it
+// reverts.
+func (c *Controller) Get() (slog.Level, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.level.Level(), c.revertAt
+}
+
+func (c *Controller) revert(gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Stop does not cancel a timer that already fired and is waiting for
+	// the lock; its revert must not undo the Set that replaced it.
+	if gen != c.gen {
+		return
+	}
+
+	c.timer = nil
+	c.revertAt = time.Time{}
+	c.level.Set(c.base)
+
+	c.log.Warn("log level reverted", slog.String("level", c.base.String()))
+}
+
+func (c *Controller) stopTimerLocked() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.revertAt = time.Time{}
+}
+
+type levelRequest struct {
+	Level string `json:"level"`
+	// TTL is a Go duration such as 5m; empty keeps the level until the
+	// next change.
+	TTL string `json:"ttl"`
+}
+
+type levelResponse struct {
+	Level    string     `json:"level"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// Handler serves GET (current level) and PUT {"level":"debug","ttl":"5m"}.
+// Requests must carry "Authorization: Bearer <token>".
+func (c *Controller) Handler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, token) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req levelRequest
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&req); err != nil {
+				http.Error(w, "invalid request body", http.StatusBadRequest)
+
+				return
+			}
+
+			var level slog.Level
+			if err := level.UnmarshalText([]byte(req.Level)); err != nil {
+				http.Error(w, "unknown level", http.StatusBadRequest)
+
+				return
+			}
+
+			var ttl time.Duration
+			if req.TTL != "" {
+				var err error
+				ttl, err = time.ParseDuration(req.TTL)
+				if err != nil || ttl < 0 || ttl > MaxTTL {
+					http.Error(w, "ttl must be a duration between 0 and 24h", http.StatusBadRequest)
+
+					return
+				}
+			}
+
+			c.Set(level, ttl)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		level, revertAt := c.Get()
+
+		resp := levelResponse{Level: level.String()}
+		if !revertAt.IsZero() {
+			resp.RevertAt = &revertAt
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+func authorized(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
@@ -0,0 +1,178 @@
+package loglevel
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestController(base slog.Level) (*Controller, *slog.LevelVar) {
+	var level slog.LevelVar
+	level.Set(base)
+
+	return NewController(slog.New(slog.NewTextHandler(io.Discard, nil)), &level), &level
+}
+
+func waitLevel(t *testing.T, level *slog.LevelVar, want slog.Level) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for level.Level() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("level = %s, want %s", level.Level(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestController_TTLRevert(t *testing.T) {
+	c, level := newTestController(slog.LevelInfo)
+
+	c.Set(slog.LevelDebug, 20*time.Millisecond)
+
+	if got, revertAt := c.Get(); got != slog.LevelDebug || revertAt.IsZero() {
+		t.Fatalf("Get() = %s, %v, want DEBUG with a revert time", got, revertAt)
+	}
+
+	waitLevel(t, level, slog.LevelInfo)
+
+	if _, revertAt := c.Get(); !revertAt.IsZero() {
+		t.Errorf("revert time = %v after revert, want zero", revertAt)
+	}
+}
+
+func TestController_SetDuringPendingRevert(t *testing.T) {
+	t.Run("stale revert is ignored", func(t *testing.T) {
+		c, level := newTestController(slog.LevelInfo)
+
+		c.Set(slog.LevelDebug, time.Hour)
+		stale := c.gen
+		c.Set(slog.LevelWarn, time.Hour)
+
+		// A timer that fired just before the second Set runs its revert
+		// only after Set releases the lock.
+		c.revert(stale)
+
+		if got := level.Level(); got != slog.LevelWarn {
+			t.Errorf("level = %s, want WARN", got)
+		}
+		if _, revertAt := c.Get(); revertAt.IsZero() {
+			t.Error("pending revert of the second Set was cleared")
+		}
+	})
+
+	t.Run("permanent level survives an earlier ttl", func(t *testing.T) {
+		c, level := newTestController(slog.LevelInfo)
+
+		c.Set(slog.LevelDebug, 10*time.Millisecond)
+		c.Set(slog.LevelError, 0)
+
+		time.Sleep(50 * time.Millisecond)
+
+		if got := level.Level(); got != slog.LevelError {
+			t.Errorf("level = %s, want ERROR", got)
+		}
+	})
+}
+
+func TestController_SetBase(t *testing.T) {
+	c, level := newTestController(slog.LevelInfo)
+
+	c.SetBase(slog.LevelWarn)
+	if got := level.Level(); got != slog.LevelWarn {
+		t.Fatalf("level = %s, want WARN", got)
+	}
+
+	c.Set(slog.LevelDebug, 30*time.Millisecond)
+	c.SetBase(slog.LevelError)
+
+	if got := level.Level(); got != slog.LevelDebug {
+		t.Fatalf("temporary level replaced by SetBase: level = %s, want DEBUG", got)
+	}
+
+	waitLevel(t, level, slog.LevelError)
+}
+
+func TestController_Handler(t *testing.T) {
+	const token = "s3cret"
+
+	tests := []struct {
+		name      string
+		method    string
+		auth      string
+		body      string
+		wantCode  int
+		wantLevel string
+		wantTTL   bool
+	}{
+		{name: "no token", method: http.MethodGet, wantCode: http.StatusUnauthorized},
+		{name: "wrong token", method: http.MethodGet, auth: "Bearer guess", wantCode: http.StatusUnauthorized},
+		{name: "not bearer", method: http.MethodGet, auth: token, wantCode: http.StatusUnauthorized},
+		{name: "get", method: http.MethodGet, auth: "Bearer " + token, wantCode: http.StatusOK, wantLevel: "INFO"},
+		{
+			name: "put", method: http.MethodPut, auth: "Bearer " + token, body: `{"level":"warn"}`,
+			wantCode: http.StatusOK, wantLevel: "WARN",
+		},
+		{
+			name: "put with ttl", method: http.MethodPut, auth: "Bearer " + token, body: `{"level":"debug","ttl":"5m"}`,
+			wantCode: http.StatusOK, wantLevel: "DEBUG", wantTTL: true,
+		},
+		{name: "bad json", method: http.MethodPut, auth: "Bearer " + token, body: `{`, wantCode: http.StatusBadRequest},
+		{name: "bad level", method: http.MethodPut, auth: "Bearer " + token, body: `{"level":"loud"}`, wantCode: http.StatusBadRequest},
+		{
+			name: "bad ttl", method: http.MethodPut, auth: "Bearer " + token, body: `{"level":"debug","ttl":"soon"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "negative ttl", method: http.MethodPut, auth: "Bearer " + token, body: `{"level":"debug","ttl":"-1m"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "ttl over max", method: http.MethodPut, auth: "Bearer " + token, body: `{"level":"debug","ttl":"25h"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{name: "post", method: http.MethodPost, auth: "Bearer " + token, wantCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, level := newTestController(slog.LevelInfo)
+			t.Cleanup(func() { c.Set(level.Level(), 0) })
+
+			req := httptest.NewRequest(tt.method, "/debug/loglevel", strings.NewReader(tt.body))
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+
+			rec := httptest.NewRecorder()
+			c.Handler(token).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d (%s)", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				if got := level.Level(); got != slog.LevelInfo {
+					t.Errorf("rejected request changed the level to %s", got)
+				}
+
+				return
+			}
+
+			var resp levelResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Level != tt.wantLevel || level.Level().String() != tt.wantLevel {
+				t.Errorf("level = %s (response %s), want %s", level.Level(), resp.Level, tt.wantLevel)
+			}
+			if (resp.RevertAt != nil) != tt.wantTTL {
+				t.Errorf("revert_at = %v, want set: %v", resp.RevertAt, tt.wantTTL)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package pkglevel
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Handler filters records by a base level, with overrides for the packages
+// the records were logged from. An override for a package also applies to
+// its subpackages; the longest matching import path wins.
+type Handler struct {
+	next      slog.Handler
+	base      slog.Leveler
+	overrides []override
+	pkgs      *sync.Map // pc -> package path
+}
+
+type debugKey struct{}
+
+// WithDebug marks ctx so records logged with it pass at debug level
+// regardless of the configured levels.
+func WithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugKey{}, true)
+}
+
+func debugForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(debugKey{}).(bool)
+
+	return forced
+}
+
+type override struct {
+	pkg   string
+	level slog.Level
+}
+
+// NewHandler wraps next, which should accept all levels. overrides maps
+// import paths (e.g. sso/internal/grpc/interceptors) to levels.
+func NewHandler(next slog.Handler, base slog.Leveler, overrides map[string]slog.Level) *Handler {
+	ovs := make([]override, 0, len(overrides))
+	for pkg, level := range overrides {
+		ovs = append(ovs, override{pkg: pkg, level: level})
+	}
+	sort.Slice(ovs, func(i, j int) bool {
+		return len(ovs[i].pkg) > len(ovs[j].pkg)
+	})
+
+	return &Handler{
+		next:      next,
+		base:      base,
+		overrides: ovs,
+		pkgs:      &sync.Map{},
+	}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= slog.LevelDebug && debugForced(ctx) {
+		return h.next.Enabled(ctx, level)
+	}
+
+	minLevel := h.base.Level()
+	for _, o := range h.overrides {
+		minLevel = min(minLevel, o.level)
+	}
+
+	return level >= minLevel && h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.levelFor(r.PC) && !(r.Level >= slog.LevelDebug && debugForced(ctx)) {
+		return nil
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+
+	return &h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+
+	return &h2
+}
+
+func (h *Handler) levelFor(pc uintptr) slog.Level {
+	if len(h.overrides) == 0 || pc == 0 {
+		return h.base.Level()
+	}
+
+	pkg := h.pkgOf(pc)
+	for _, o := range h.overrides {
+		if pkg == o.pkg || strings.HasPrefix(pkg, o.pkg+"/") {
+			return o.level
+		}
+	}
+
+	return h.base.Level()
+}
+
+func (h *Handler) pkgOf(pc uintptr) string {
+	if pkg, ok := h.pkgs.Load(pc); ok {
+		return pkg.(string)
+	}
+
+	fs := runtime.CallersFrames([]uintptr{pc})
+	f, _ := fs.Next()
+
+	pkg := packagePath(f.Function)
+	h.pkgs.Store(pc, pkg)
+
+	return pkg
+}
+
+// packagePath extracts the import path from a function name such as
+// sso/internal/app/grpc.(*App).Run. The runtime escapes dots in the last
+// path element (gopkg.in/natefinch/lumberjack%2ev2.(*Logger).Write), so
+// the first dot after the last slash ends the path and the escapes are
+// undone afterwards.
+func packagePath(fn string) string {
+	slash := strings.LastIndexByte(fn, '/')
+	if dot := strings.IndexByte(fn[slash+1:], '.'); dot >= 0 {
+		fn = fn[:slash+1+dot]
+	}
+
+	if strings.Contains(fn, "%") {
+		if pkg, err := url.PathUnescape(fn); err == nil {
+			return pkg
+		}
+	}
+
+	return fn
+}
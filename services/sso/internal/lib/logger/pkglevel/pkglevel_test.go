@@ -0,0 +1,165 @@
+package pkglevel
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const thisPkg = "sso/internal/lib/logger/pkglevel"
+
+func TestPackagePath(t *testing.T) {
+	tests := []struct {
+		fn   string
+		want string
+	}{
+		{fn: "sso/internal/app/grpc.(*App).Run", want: "sso/internal/app/grpc"},
+		{fn: "sso/internal/app/grpc.New.func1", want: "sso/internal/app/grpc"},
+		{fn: "sso/internal/lib/cache.Get[...]", want: "sso/internal/lib/cache"},
+		{fn: "main.main", want: "main"},
+		{fn: "log/slog.(*Logger).log", want: "log/slog"},
+		{fn: "gopkg.in/natefinch/lumberjack%2ev2.(*Logger).Write", want: "gopkg.in/natefinch/lumberjack.v2"},
+		{fn: "github.com/a/b.v1/c.F", want: "github.com/a/b.v1/c"},
+		{fn: "pkgonly", want: "pkgonly"},
+	}
+
+	for _, tt := range tests {
+		if got := packagePath(tt.fn); got != tt.want {
+			t.Errorf("packagePath(%q) = %q, want %q", tt.fn, got, tt.want)
+		}
+	}
+}
+
+func pcOf(fn any) uintptr {
+	return reflect.ValueOf(fn).Pointer()
+}
+
+func callerPC() uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+
+	return pcs[0]
+}
+
+func TestHandler_LevelFor(t *testing.T) {
+	thisPC := callerPC()
+	slogPC := pcOf(slog.New)
+	lumberjackPC := pcOf((*lumberjack.Logger).Write)
+
+	tests := []struct {
+		name      string
+		overrides map[string]slog.Level
+		pc        uintptr
+		want      slog.Level
+	}{
+		{name: "no overrides", pc: thisPC, want: slog.LevelInfo},
+		{
+			name:      "exact package",
+			overrides: map[string]slog.Level{thisPkg: slog.LevelDebug},
+			pc:        thisPC,
+			want:      slog.LevelDebug,
+		},
+		{
+			name:      "subpackage",
+			overrides: map[string]slog.Level{"sso/internal/lib/logger": slog.LevelError},
+			pc:        thisPC,
+			want:      slog.LevelError,
+		},
+		{
+			name: "longest prefix wins",
+			overrides: map[string]slog.Level{
+				"sso":                     slog.LevelError,
+				"sso/internal/lib/logger": slog.LevelWarn,
+				thisPkg:                   slog.LevelDebug,
+				"sso/internal":            slog.LevelError,
+			},
+			pc:   thisPC,
+			want: slog.LevelDebug,
+		},
+		{
+			name:      "prefix is not a path boundary",
+			overrides: map[string]slog.Level{"sso/internal/lib/logger/pkg": slog.LevelDebug},
+			pc:        thisPC,
+			want:      slog.LevelInfo,
+		},
+		{
+			name:      "base fallback",
+			overrides: map[string]slog.Level{"sso/internal/app": slog.LevelDebug},
+			pc:        slogPC,
+			want:      slog.LevelInfo,
+		},
+		{
+			name:      "dotted import path",
+			overrides: map[string]slog.Level{"gopkg.in/natefinch/lumberjack.v2": slog.LevelError},
+			pc:        lumberjackPC,
+			want:      slog.LevelError,
+		},
+		{
+			name:      "no pc",
+			overrides: map[string]slog.Level{thisPkg: slog.LevelDebug},
+			want:      slog.LevelInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(slog.DiscardHandler, slog.LevelInfo, tt.overrides)
+
+			if got := h.levelFor(tt.pc); got != tt.want {
+				t.Errorf("levelFor() = %s, want %s (package %q)", got, tt.want, h.pkgOf(tt.pc))
+			}
+		})
+	}
+}
+
+func TestHandler_Filtering(t *testing.T) {
+	var level slog.LevelVar
+	level.Set(slog.LevelWarn)
+
+	var buf bytes.Buffer
+	next := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.Level(-100)})
+
+	tests := []struct {
+		name      string
+		overrides map[string]slog.Level
+		ctx       context.Context
+		level     slog.Level
+		want      bool
+	}{
+		{name: "below base", ctx: context.Background(), level: slog.LevelInfo},
+		{name: "at base", ctx: context.Background(), level: slog.LevelWarn, want: true},
+		{
+			name:      "override lowers this package",
+			overrides: map[string]slog.Level{thisPkg: slog.LevelDebug},
+			ctx:       context.Background(),
+			level:     slog.LevelDebug,
+			want:      true,
+		},
+		{
+			name:      "override for another package",
+			overrides: map[string]slog.Level{"sso/internal/app": slog.LevelDebug},
+			ctx:       context.Background(),
+			level:     slog.LevelDebug,
+		},
+		{name: "forced debug", ctx: WithDebug(context.Background()), level: slog.LevelDebug, want: true},
+		{name: "forced debug keeps trace out", ctx: WithDebug(context.Background()), level: slog.LevelDebug - 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+
+			slog.New(NewHandler(next, &level, tt.overrides)).Log(tt.ctx, tt.level, "hello")
+
+			if got := strings.Contains(buf.String(), "hello"); got != tt.want {
+				t.Errorf("logged = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package slogctx
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"sso/internal/lib/requestid"
+)
+
+// Handler adds request-scoped values stored in the context (request ID,
+// trace and span IDs) to every record passed to the wrapped handler. The
+// values always stay top-level, whatever groups the logger has opened.
+type Handler struct {
+	next slog.Handler
+	// goas holds WithGroup and WithAttrs calls made after the first group.
+	// They are applied per record, below the injected values; attrs added
+	// before any group go straight to next.
+	goas []groupOrAttrs
+}
+
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+func NewHandler(h slog.Handler) *Handler {
+	return &Handler{next: h}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	var ctxAttrs []slog.Attr
+
+	if id, ok := requestid.FromContext(ctx); ok {
+		ctxAttrs = append(ctxAttrs, slog.String("request_id", id))
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		ctxAttrs = append(ctxAttrs,
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+
+	if len(h.goas) == 0 {
+		r.AddAttrs(ctxAttrs...)
+
+		return h.next.Handle(ctx, r)
+	}
+
+	// Rebuild the grouped part of the record from the innermost group out.
+	inner := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		inner = append(inner, a)
+
+		return true
+	})
+
+	for i := len(h.goas) - 1; i >= 0; i-- {
+		if g := h.goas[i]; g.group != "" {
+			inner = []slog.Attr{{Key: g.group, Value: slog.GroupValue(inner...)}}
+		} else {
+			inner = append(g.attrs[:len(g.attrs):len(g.attrs)], inner...)
+		}
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(ctxAttrs...)
+	nr.AddAttrs(inner...)
+
+	return h.next.Handle(ctx, nr)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	if len(h.goas) == 0 {
+		return &Handler{next: h.next.WithAttrs(attrs)}
+	}
+
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *Handler) with(goa groupOrAttrs) *Handler {
+	goas := make([]groupOrAttrs, len(h.goas)+1)
+	copy(goas, h.goas)
+	goas[len(h.goas)] = goa
+
+	return &Handler{next: h.next, goas: goas}
+}
@@ -0,0 +1,131 @@
+package slogctx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"testing/slogtest"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"sso/internal/lib/requestid"
+)
+
+func TestHandler_Conformance(t *testing.T) {
+	var buf bytes.Buffer
+
+	slogtest.Run(t,
+		func(*testing.T) slog.Handler {
+			buf.Reset()
+
+			return NewHandler(slog.NewJSONHandler(&buf, nil))
+		},
+		func(t *testing.T) map[string]any {
+			var m map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+
+			return m
+		},
+	)
+}
+
+func TestHandler_RequestIDStaysTopLevel(t *testing.T) {
+	var buf bytes.Buffer
+
+	log := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil))).
+		With("env", "local").
+		WithGroup("req").
+		With("method", "Login").
+		WithGroup("user")
+
+	ctx := requestid.NewContext(context.Background(), "abc")
+	log.InfoContext(ctx, "msg", "id", 1)
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got["request_id"] != "abc" {
+		t.Errorf("request_id = %v, want top-level abc in %s", got["request_id"], buf.String())
+	}
+	if got["env"] != "local" {
+		t.Errorf("env = %v, want local", got["env"])
+	}
+
+	req, _ := got["req"].(map[string]any)
+	if req["method"] != "Login" {
+		t.Errorf("req.method = %v, want Login in %s", req["method"], buf.String())
+	}
+	if _, ok := req["request_id"]; ok {
+		t.Errorf("request_id leaked into group: %s", buf.String())
+	}
+
+	user, _ := req["user"].(map[string]any)
+	if user["id"] != float64(1) {
+		t.Errorf("req.user.id = %v, want 1 in %s", user["id"], buf.String())
+	}
+}
+
+func TestHandler_TraceIDsStayTopLevel(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+
+	tests := []struct {
+		name string
+		sc   trace.SpanContext
+		want bool
+	}{
+		{
+			name: "valid span",
+			sc:   trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled}),
+			want: true,
+		},
+		{name: "no span"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			log := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil))).
+				WithGroup("req").
+				With("method", "Login")
+
+			ctx := trace.ContextWithSpanContext(context.Background(), tt.sc)
+			log.InfoContext(ctx, "msg")
+
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			_, hasTrace := got["trace_id"]
+			_, hasSpan := got["span_id"]
+			if !tt.want {
+				if hasTrace || hasSpan {
+					t.Errorf("trace attrs logged without a span: %s", buf.String())
+				}
+
+				return
+			}
+
+			if got["trace_id"] != traceID.String() || got["span_id"] != spanID.String() {
+				t.Errorf("trace_id, span_id = %v, %v, want top-level %s, %s in %s",
+					got["trace_id"], got["span_id"], traceID, spanID, buf.String())
+			}
+
+			req, _ := got["req"].(map[string]any)
+			if _, ok := req["trace_id"]; ok {
+				t.Errorf("trace_id leaked into group: %s", buf.String())
+			}
+			if req["method"] != "Login" {
+				t.Errorf("req.method = %v, want Login", req["method"])
+			}
+		})
+	}
+}
@@ -1,98 +1,338 @@
 package slogpretty
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	stdLog "log"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 )
 
+const timeFormat = "[15:04:05.000]"
+
 type PrettyHandlerOptions struct {
 	SlogOpts *slog.HandlerOptions
+	// NoColor disables colors. Colors are also disabled when the NO_COLOR
+	// env var is set or the output is not a terminal.
+	NoColor bool
 }
 
+// PrettyHandler is a human-readable slog.Handler for local development:
+// a colored "[time] LEVEL: message" line followed by the attributes as
+// indented JSON, with groups rendered as nested objects.
 type PrettyHandler struct {
-	opts PrettyHandlerOptions
-	slog.Handler
-	l     *stdLog.Logger
+	opts     PrettyHandlerOptions
+	l        *stdLog.Logger
+	colorize bool
+	// goas holds the WithGroup and WithAttrs calls in order, so attrs end
+	// up in the group that was open when they were added.
+	goas []groupOrAttrs
+}
+
+type groupOrAttrs struct {
+	group string
 	attrs []slog.Attr
 }
 
 func (opts PrettyHandlerOptions) NewPrettyHandler(
 	out io.Writer,
 ) *PrettyHandler {
+	if opts.SlogOpts == nil {
+		opts.SlogOpts = &slog.HandlerOptions{}
+	}
+
 	h := &PrettyHandler{
-		Handler: slog.NewJSONHandler(out, opts.SlogOpts),
-		l:       stdLog.New(out, "", 0),
+		opts:     opts,
+		l:        stdLog.New(out, "", 0),
+		colorize: !opts.NoColor && os.Getenv("NO_COLOR") == "" && isTerminal(out),
 	}
 
 	return h
 }
 
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+func (h *PrettyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.SlogOpts.Level != nil {
+		minLevel = h.opts.SlogOpts.Level.Level()
+	}
+
+	return level >= minLevel
+}
+
 func (h *PrettyHandler) Handle(_ context.Context, r slog.Record) error {
-	level := r.Level.String() + ":"
+	var head []string
 
-	switch r.Level {
-	case slog.LevelDebug:
-		level = color.MagentaString(level)
-	case slog.LevelInfo:
-		level = color.BlueString(level)
-	case slog.LevelWarn:
-		level = color.YellowString(level)
-	case slog.LevelError:
-		level = color.RedString(level)
+	if !r.Time.IsZero() {
+		if a, ok := h.replaceBuiltin(slog.Time(slog.TimeKey, r.Time)); ok {
+			if a.Value.Kind() == slog.KindTime {
+				head = append(head, a.Value.Time().Format(timeFormat))
+			} else {
+				head = append(head, a.Value.String())
+			}
+		}
+	}
+
+	if a, ok := h.replaceBuiltin(slog.Any(slog.LevelKey, r.Level)); ok {
+		head = append(head, h.colorLevel(r.Level, a.Value.String()+":"))
+	}
+
+	if a, ok := h.replaceBuiltin(slog.String(slog.MessageKey, r.Message)); ok {
+		head = append(head, h.paint(color.FgCyan, a.Value.String()))
+	}
+
+	fields := &object{}
+
+	if h.opts.SlogOpts.AddSource && r.PC != 0 {
+		if a, ok := h.replaceBuiltin(slog.String(slog.SourceKey, source(r.PC))); ok {
+			fields.add(a.Key, leafValue(a.Value))
+		}
 	}
 
-	fields := make(map[string]interface{}, r.NumAttrs())
+	cur := fields
+	var groups []string
+
+	for _, goa := range h.goas {
+		if goa.group != "" {
+			child := &object{}
+			cur.add(goa.group, child)
+			cur = child
+			groups = append(groups, goa.group)
+
+			continue
+		}
+
+		for _, a := range goa.attrs {
+			h.addAttr(cur, a, groups)
+		}
+	}
 
 	r.Attrs(func(a slog.Attr) bool {
-		fields[a.Key] = a.Value.Any()
+		h.addAttr(cur, a, groups)
 
 		return true
 	})
 
-	for _, a := range h.attrs {
-		fields[a.Key] = a.Value.Any()
-	}
+	fields.prune()
 
-	var b []byte
-	var err error
+	args := make([]any, 0, len(head)+1)
+	for _, s := range head {
+		args = append(args, s)
+	}
 
-	if len(fields) > 0 {
-		b, err = json.MarshalIndent(fields, "", "  ")
+	if len(fields.fields) > 0 {
+		b, err := json.MarshalIndent(fields, "", "  ")
 		if err != nil {
 			return err
 		}
-	}
 
-	timeStr := r.Time.Format("[15:05:05.000]")
-	msg := color.CyanString(r.Message)
+		args = append(args, h.paint(color.FgWhite, string(b)))
+	}
 
-	h.l.Println(
-		timeStr,
-		level,
-		msg,
-		color.WhiteString(string(b)),
-	)
+	h.l.Println(args...)
 
 	return nil
 }
 
 func (h *PrettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &PrettyHandler{
-		Handler: h.Handler,
-		l:       h.l,
-		attrs:   attrs,
+	if len(attrs) == 0 {
+		return h
 	}
+
+	return h.with(groupOrAttrs{attrs: attrs})
 }
 
 func (h *PrettyHandler) WithGroup(name string) slog.Handler {
-	// TODO: implement
-	return &PrettyHandler{
-		Handler: h.Handler.WithGroup(name),
-		l:       h.l,
+	if name == "" {
+		return h
+	}
+
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *PrettyHandler) with(goa groupOrAttrs) *PrettyHandler {
+	h2 := *h
+	h2.goas = make([]groupOrAttrs, len(h.goas)+1)
+	copy(h2.goas, h.goas)
+	h2.goas[len(h.goas)] = goa
+
+	return &h2
+}
+
+// addAttr resolves a, applies ReplaceAttr and adds the result to obj,
+// expanding groups into nested objects.
+func (h *PrettyHandler) addAttr(obj *object, a slog.Attr, groups []string) {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() != slog.KindGroup && h.opts.SlogOpts.ReplaceAttr != nil {
+		a = h.opts.SlogOpts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+
+	if a.Equal(slog.Attr{}) {
+		return
 	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		obj.add(a.Key, leafValue(a.Value))
+
+		return
+	}
+
+	attrs := a.Value.Group()
+	if len(attrs) == 0 {
+		return
+	}
+
+	if a.Key == "" {
+		for _, ga := range attrs {
+			h.addAttr(obj, ga, groups)
+		}
+
+		return
+	}
+
+	child := &object{}
+	obj.add(a.Key, child)
+
+	groups = append(groups[:len(groups):len(groups)], a.Key)
+	for _, ga := range attrs {
+		h.addAttr(child, ga, groups)
+	}
+}
+
+// replaceBuiltin applies ReplaceAttr to a built-in attribute. It reports
+// false if the attribute was removed.
+func (h *PrettyHandler) replaceBuiltin(a slog.Attr) (slog.Attr, bool) {
+	if h.opts.SlogOpts.ReplaceAttr == nil {
+		return a, true
+	}
+
+	a = h.opts.SlogOpts.ReplaceAttr(nil, a)
+	a.Value = a.Value.Resolve()
+
+	return a, a.Key != ""
+}
+
+func (h *PrettyHandler) colorLevel(level slog.Level, s string) string {
+	switch {
+	case level < slog.LevelInfo:
+		return h.paint(color.FgMagenta, s)
+	case level < slog.LevelWarn:
+		return h.paint(color.FgBlue, s)
+	case level < slog.LevelError:
+		return h.paint(color.FgYellow, s)
+	default:
+		return h.paint(color.FgRed, s)
+	}
+}
+
+func (h *PrettyHandler) paint(attr color.Attribute, s string) string {
+	c := color.New(attr)
+	if h.colorize {
+		c.EnableColor()
+	} else {
+		c.DisableColor()
+	}
+
+	return c.Sprint(s)
+}
+
+func source(pc uintptr) string {
+	fs := runtime.CallersFrames([]uintptr{pc})
+	f, _ := fs.Next()
+
+	return fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(f.File)), filepath.Base(f.File), f.Line)
+}
+
+func leafValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case json.Marshaler:
+			return x
+		case error:
+			return x.Error()
+		}
+	}
+
+	return v.Any()
+}
+
+// object is a JSON object that keeps its keys in insertion order.
+type object struct {
+	fields []field
+}
+
+type field struct {
+	key string
+	val any
+}
+
+func (o *object) add(key string, val any) {
+	o.fields = append(o.fields, field{key: key, val: val})
+}
+
+// prune drops nested objects that ended up without any fields.
+func (o *object) prune() {
+	kept := o.fields[:0]
+	for _, f := range o.fields {
+		if child, ok := f.val.(*object); ok {
+			child.prune()
+			if len(child.fields) == 0 {
+				continue
+			}
+		}
+		kept = append(kept, f)
+	}
+	o.fields = kept
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+	for i, f := range o.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+
+		v, err := json.Marshal(f.val)
+		if err != nil {
+			// Fall back to the fmt form for values JSON cannot encode.
+			v, _ = json.Marshal(fmt.Sprint(f.val))
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
 }
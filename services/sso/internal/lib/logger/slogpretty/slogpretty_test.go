@@ -0,0 +1,113 @@
+package slogpretty
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+// recordWriter keeps every Write separately; the handler writes each
+// record with a single Write.
+type recordWriter struct {
+	records []string
+}
+
+func (w *recordWriter) Write(p []byte) (int, error) {
+	w.records = append(w.records, string(p))
+
+	return len(p), nil
+}
+
+func newTestHandler(w *recordWriter, opts *slog.HandlerOptions) *PrettyHandler {
+	return PrettyHandlerOptions{SlogOpts: opts, NoColor: true}.NewPrettyHandler(w)
+}
+
+// parseRecord turns "[time] LEVEL: msg {json}" back into a map.
+func parseRecord(t *testing.T, s string) map[string]any {
+	t.Helper()
+
+	m := make(map[string]any)
+	s = strings.TrimSuffix(s, "\n")
+
+	if strings.HasPrefix(s, "[") {
+		ts, rest, _ := strings.Cut(s, "] ")
+		m[slog.TimeKey] = ts + "]"
+		s = rest
+	}
+
+	level, rest, _ := strings.Cut(s, ": ")
+	m[slog.LevelKey] = level
+
+	msg, js, ok := strings.Cut(rest, " {\n")
+	m[slog.MessageKey] = msg
+
+	if ok {
+		if err := json.Unmarshal([]byte("{\n"+js), &m); err != nil {
+			t.Fatalf("parse %q: %v", s, err)
+		}
+	}
+
+	return m
+}
+
+func TestPrettyHandler_Conformance(t *testing.T) {
+	var w *recordWriter
+
+	slogtest.Run(t,
+		func(*testing.T) slog.Handler {
+			w = &recordWriter{}
+
+			return newTestHandler(w, &slog.HandlerOptions{AddSource: true})
+		},
+		func(t *testing.T) map[string]any {
+			if len(w.records) != 1 {
+				t.Fatalf("got %d records, want 1", len(w.records))
+			}
+
+			return parseRecord(t, w.records[0])
+		},
+	)
+}
+
+func TestPrettyHandler_Output(t *testing.T) {
+	w := &recordWriter{}
+	h := newTestHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+
+	log := slog.New(h).With("z", 1).With("a", 2).WithGroup("req")
+	log.Debug("filtered")
+	log.Info("done", "m", "x", "b", "y")
+
+	if len(w.records) != 1 {
+		t.Fatalf("got %d records, want 1 (debug must be filtered)", len(w.records))
+	}
+
+	var compact bytes.Buffer
+	_, js, _ := strings.Cut(w.records[0], " {\n")
+	if err := json.Compact(&compact, []byte("{\n"+js)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"z":1,"a":2,"req":{"m":"x","b":"y"}}`
+	if compact.String() != want {
+		t.Errorf("got %s, want %s", compact.String(), want)
+	}
+}
+
+func TestPrettyHandler_Time(t *testing.T) {
+	w := &recordWriter{}
+	h := newTestHandler(w, nil)
+
+	r := slog.NewRecord(time.Date(2025, 1, 2, 10, 20, 30, 123e6, time.Local), slog.LevelInfo, "msg", 0)
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "[10:20:30.123] INFO: msg\n"; w.records[0] != want {
+		t.Errorf("got %q, want %q", w.records[0], want)
+	}
+}
@@ -0,0 +1,281 @@
+package slogredact
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	redacted    = "[REDACTED]"
+	redactedJWT = "[REDACTED JWT]"
+)
+
+var (
+	jwtRe   = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	emailRe = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// phoneRe and localPhoneRe match phones inside free text: international
+	// numbers with a leading + and domestic 11-digit numbers starting with 8,
+	// such as 89123456789 or 8 (912) 345-67-89. Other bare digit runs are
+	// left alone so IDs and counters survive; values of phone keys are
+	// masked whatever their format.
+	phoneRe      = regexp.MustCompile(`\+\d[\d\s()-]{7,}\d`)
+	localPhoneRe = regexp.MustCompile(`\b8[\s(-]*\d{3}[\s)-]*\d{3}[\s-]*\d{2}[\s-]*\d{2}\b`)
+)
+
+// Handler masks secrets and personal data before passing records on:
+//   - values of the configured keys (case-insensitive) are replaced entirely;
+//   - JWT-looking strings are replaced in any value and in the message;
+//   - emails keep their first letter and domain, phones their last two digits;
+//   - values of keys containing "phone" are masked whatever their kind, so
+//     slog.Int64("phone", 79123456789) is covered too;
+//   - maps, structs, slices and arrays are walked through their JSON form
+//     with the same rules, which costs an encode and decode per such attr;
+//     values that cannot be encoded are replaced entirely.
+type Handler struct {
+	next slog.Handler
+	keys map[string]struct{}
+}
+
+func NewHandler(next slog.Handler, keys []string) *Handler {
+	m := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		m[strings.ToLower(k)] = struct{}{}
+	}
+
+	return &Handler{next: next, keys: m}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, redactString(r.Message), r.PC)
+
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(h.redactAttr(a))
+
+		return true
+	})
+
+	return h.next.Handle(ctx, nr)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redactedAttrs[i] = h.redactAttr(a)
+	}
+
+	return &Handler{next: h.next.WithAttrs(redactedAttrs), keys: h.keys}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), keys: h.keys}
+}
+
+func (h *Handler) redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	key := strings.ToLower(a.Key)
+
+	if _, ok := h.keys[key]; ok {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		res := make([]slog.Attr, len(attrs))
+		for i, ga := range attrs {
+			res[i] = h.redactAttr(ga)
+		}
+
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(res...)}
+	case slog.KindString:
+		return slog.String(a.Key, redactValue(key, a.Value.String()))
+	case slog.KindInt64, slog.KindUint64:
+		if strings.Contains(key, "phone") {
+			return slog.String(a.Key, maskPhone(a.Value.String()))
+		}
+	case slog.KindFloat64:
+		if strings.Contains(key, "phone") {
+			return slog.String(a.Key, maskPhone(strconv.FormatFloat(a.Value.Float64(), 'f', -1, 64)))
+		}
+	case slog.KindAny:
+		// Errors, Stringers and other scalars often wrap request data;
+		// check their text and only replace the value when something had
+		// to be masked.
+		var s string
+		switch v := a.Value.Any().(type) {
+		case error:
+			s = v.Error()
+		case fmt.Stringer:
+			s = v.String()
+		default:
+			if structured(v) {
+				if rv, changed := h.redactStructured(key, v); changed {
+					return slog.Any(a.Key, rv)
+				}
+
+				return a
+			}
+			s = fmt.Sprint(v)
+		}
+
+		if rs := redactValue(key, s); rs != s {
+			return slog.String(a.Key, rs)
+		}
+	}
+
+	return a
+}
+
+// structured reports whether v is a map, struct, slice or array, possibly
+// behind pointers. Only those are worth the JSON round-trip.
+func structured(v any) bool {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array:
+		return true
+	}
+
+	return false
+}
+
+// redactStructured applies the redaction rules to the JSON form of v,
+// including the keys of nested objects. It reports whether anything was
+// masked; if so the result replaces v in the record.
+func (h *Handler) redactStructured(key string, v any) (any, bool) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return redacted, true
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return redacted, true
+	}
+
+	return h.redactJSON(key, decoded)
+}
+
+func (h *Handler) redactJSON(key string, v any) (any, bool) {
+	switch x := v.(type) {
+	case map[string]any:
+		changed := false
+		for k, val := range x {
+			lk := strings.ToLower(k)
+			if _, ok := h.keys[lk]; ok {
+				x[k] = redacted
+				changed = true
+
+				continue
+			}
+			if rv, c := h.redactJSON(lk, val); c {
+				x[k] = rv
+				changed = true
+			}
+		}
+
+		return x, changed
+	case []any:
+		changed := false
+		for i, val := range x {
+			if rv, c := h.redactJSON(key, val); c {
+				x[i] = rv
+				changed = true
+			}
+		}
+
+		return x, changed
+	case string:
+		s := redactValue(key, x)
+
+		return s, s != x
+	case json.Number:
+		if strings.Contains(key, "phone") {
+			return maskPhone(x.String()), true
+		}
+	}
+
+	return v, false
+}
+
+// redactValue masks s as the value of key: phone keys are masked whatever
+// the format, anything else only where a pattern matches.
+func redactValue(key, s string) string {
+	if strings.Contains(key, "phone") {
+		return maskPhone(s)
+	}
+
+	return redactString(s)
+}
+
+func redactString(s string) string {
+	if strings.Contains(s, "eyJ") {
+		s = jwtRe.ReplaceAllString(s, redactedJWT)
+	}
+	if strings.Contains(s, "@") {
+		s = emailRe.ReplaceAllStringFunc(s, maskEmail)
+	}
+	if strings.Contains(s, "+") {
+		s = phoneRe.ReplaceAllStringFunc(s, maskPhone)
+	}
+	if strings.Contains(s, "8") {
+		s = localPhoneRe.ReplaceAllStringFunc(s, maskPhone)
+	}
+
+	return s
+}
+
+// maskEmail turns john.doe@uni.ru into j***@uni.ru.
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return redacted
+	}
+
+	return local[:1] + "***@" + domain
+}
+
+// maskPhone replaces every digit but the last two, keeping the formatting:
+// +7 912 345-67-89 becomes +* *** ***-**-89.
+func maskPhone(phone string) string {
+	digits := 0
+	for _, c := range phone {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+
+	b := []byte(phone)
+	seen := 0
+	for i, c := range b {
+		if c < '0' || c > '9' {
+			continue
+		}
+		seen++
+		if seen <= digits-2 {
+			b[i] = '*'
+		}
+	}
+
+	return string(b)
+}
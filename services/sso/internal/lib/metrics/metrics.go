@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "sso"
+
+var (
+	GRPCHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "server_handled_total",
+		Help:      "Total number of RPCs completed on the server, by method and code.",
+	}, []string{"method", "code"})
+
+	GRPCHandlingSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "server_handling_seconds",
+		Help:      "Time taken by the server to handle an RPC, by method and code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "logins_total",
+		Help:      "Login attempts by app, result (success/failure) and failure reason.",
+	}, []string{"app", "result", "reason"})
+
+	TokensIssued = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "tokens_issued_total",
+		Help:      "Tokens issued by app.",
+	}, []string{"app"})
+
+	StorageQuerySeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "query_duration_seconds",
+		Help:      "Storage query latency by operation.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"op"})
+
+	ActiveSessions = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "active_sessions",
+		Help:      "Number of currently active sessions.",
+	})
+)
+
+func LoginSucceeded(app string) {
+	Logins.WithLabelValues(app, "success", "").Inc()
+}
+
+// LoginFailed counts a failed login. Keep reason to a small fixed set
+// (invalid_credentials, user_not_found, ...) to bound label cardinality.
+func LoginFailed(app, reason string) {
+	Logins.WithLabelValues(app, "failure", reason).Inc()
+}
+
+func TokenIssued(app string) {
+	TokensIssued.WithLabelValues(app).Inc()
+}
+
+// ObserveQuery records the latency of a storage operation started at start.
+//
+//	defer metrics.ObserveQuery(op, time.Now())
+func ObserveQuery(op string, start time.Time) {
+	StorageQuerySeconds.WithLabelValues(op).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,130 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var ErrNotFound = errors.New("secret not found")
+
+// Secret is a sensitive config value. Before resolution it holds a
+// reference (file:///path, env://NAME or vault://mount/path#key), after it
+// holds the value. It never prints, logs or marshals its content.
+type Secret string
+
+// Value returns the secret in clear text.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// IsReference reports whether s is a scheme://path reference rather than
+// a literal value.
+func (s Secret) IsReference() bool {
+	scheme, _, ok := strings.Cut(string(s), "://")
+
+	return ok && scheme != ""
+}
+
+// Fetcher looks up a secret by the part of a reference after "scheme://".
+type Fetcher interface {
+	Fetch(ctx context.Context, path string) (string, error)
+}
+
+type FetcherFunc func(ctx context.Context, path string) (string, error)
+
+func (f FetcherFunc) Fetch(ctx context.Context, path string) (string, error) {
+	return f(ctx, path)
+}
+
+// Resolver replaces secret references with their values.
+type Resolver struct {
+	fetchers map[string]Fetcher
+}
+
+// NewResolver returns a resolver for file:// and env:// references. Other
+// schemes are added with Register.
+func NewResolver() *Resolver {
+	return &Resolver{
+		fetchers: map[string]Fetcher{
+			"file": FetcherFunc(fetchFile),
+			"env":  FetcherFunc(fetchEnv),
+		},
+	}
+}
+
+func (r *Resolver) Register(scheme string, f Fetcher) {
+	r.fetchers[scheme] = f
+}
+
+// Resolve resolves s in place. Values without a scheme are kept as
+// literals so local configs can still inline throwaway keys.
+func (r *Resolver) Resolve(ctx context.Context, s *Secret) error {
+	const op = "secrets.Resolve"
+
+	if !s.IsReference() {
+		return nil
+	}
+
+	scheme, path, _ := strings.Cut(string(*s), "://")
+
+	f, ok := r.fetchers[scheme]
+	if !ok {
+		return fmt.Errorf("%s: unsupported scheme %q", op, scheme)
+	}
+
+	v, err := f.Fetch(ctx, path)
+	if err != nil {
+		return fmt.Errorf("%s: %s://%s: %w", op, scheme, path, err)
+	}
+
+	*s = Secret(v)
+
+	return nil
+}
+
+func fetchFile(_ context.Context, path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+func fetchEnv(_ context.Context, name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return v, nil
+}
@@ -0,0 +1,123 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TEST_SECRETS_KEY", "from-env")
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr error
+		errText string
+	}{
+		{name: "literal", ref: "inline", want: "inline"},
+		{name: "empty", ref: "", want: ""},
+		{name: "file", ref: "file://" + keyFile, want: "from-file"},
+		{name: "missing file", ref: "file://" + filepath.Join(dir, "nope"), wantErr: ErrNotFound},
+		{name: "env", ref: "env://TEST_SECRETS_KEY", want: "from-env"},
+		{name: "missing env", ref: "env://TEST_SECRETS_UNSET", wantErr: ErrNotFound},
+		{name: "unknown scheme", ref: "s3://bucket/key", errText: `unsupported scheme "s3"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Secret(tt.ref)
+			err := NewResolver().Resolve(context.Background(), &s)
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Resolve() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.errText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Fatalf("Resolve() error = %v, want it to contain %q", err, tt.errText)
+				}
+			case err != nil:
+				t.Fatalf("Resolve() error = %v", err)
+			case s.Value() != tt.want:
+				t.Errorf("Value() = %q, want %q", s.Value(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSecret_IsReference(t *testing.T) {
+	tests := map[string]bool{
+		"":                     false,
+		"inline":               false,
+		"env://NAME":           true,
+		"file:///run/key":      true,
+		"vault://secret/a#key": true,
+		"://no-scheme":         false,
+	}
+
+	for s, want := range tests {
+		if got := Secret(s).IsReference(); got != want {
+			t.Errorf("Secret(%q).IsReference() = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestSecret_Redacted(t *testing.T) {
+	const value = "hunter2"
+
+	s := Secret(value)
+
+	cfg := struct {
+		Name string
+		Key  Secret `json:"key"`
+	}{Name: "sso", Key: s}
+
+	var logBuf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&logBuf, nil)).Info("config", "key", s, "cfg", cfg)
+
+	jsonOut, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputs := map[string]string{
+		"%s":   fmt.Sprintf("%s", s),
+		"%v":   fmt.Sprintf("%v", s),
+		"%+v":  fmt.Sprintf("%+v", cfg),
+		"%#v":  fmt.Sprintf("%#v", cfg),
+		"json": string(jsonOut),
+		"slog": logBuf.String(),
+	}
+
+	for name, out := range outputs {
+		if strings.Contains(out, value) {
+			t.Errorf("%s leaks the secret: %s", name, out)
+		}
+		if !strings.Contains(out, redacted) {
+			t.Errorf("%s = %s, want %s", name, out, redacted)
+		}
+	}
+
+	if s.Value() != value {
+		t.Errorf("Value() = %q, want %q", s.Value(), value)
+	}
+	if Secret("").String() != "" {
+		t.Error("empty secret should print as empty")
+	}
+}
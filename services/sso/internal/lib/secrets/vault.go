@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Vault fetches secrets from a Vault-compatible KV v2 HTTP API. References
+// look like vault://<mount>/<path>#<key>, for example
+// vault://secret/sso/jwt#signing_key reads key signing_key of
+// GET <addr>/v1/secret/data/sso/jwt.
+type Vault struct {
+	addr   string
+	token  string
+	client *http.Client
+}
+
+func NewVault(addr, token string, client *http.Client) *Vault {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Vault{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		client: client,
+	}
+}
+
+type kvResponse struct {
+	Data struct {
+		Data map[string]any `json:"data"`
+	} `json:"data"`
+}
+
+func (v *Vault) Fetch(ctx context.Context, ref string) (string, error) {
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok || key == "" {
+		return "", fmt.Errorf("reference must end with #key")
+	}
+
+	mount, rest, ok := strings.Cut(path, "/")
+	if !ok || mount == "" || rest == "" {
+		return "", fmt.Errorf("reference must be <mount>/<path>#<key>")
+	}
+
+	u := v.addr + "/v1/" + url.PathEscape(mount) + "/data/" + rest
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	var body kvResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode vault response: %w", err)
+	}
+
+	val, ok := body.Data.Data[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	s, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("key %q is not a string", key)
+	}
+
+	return s, nil
+}
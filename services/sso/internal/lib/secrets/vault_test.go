@@ -0,0 +1,98 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestVault(t *testing.T) *Vault {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/sso/jwt":
+			_, _ = w.Write([]byte(`{"data":{"data":{"signing_key":"k3y","rotations":3}}}`))
+		case "/v1/secret/data/broken":
+			_, _ = w.Write([]byte(`not json`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return NewVault(srv.URL+"/", "root", srv.Client())
+}
+
+func TestVault_Fetch(t *testing.T) {
+	v := newTestVault(t)
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr error
+		errText string
+	}{
+		{name: "ok", ref: "secret/sso/jwt#signing_key", want: "k3y"},
+		{name: "missing path", ref: "secret/sso/other#signing_key", wantErr: ErrNotFound},
+		{name: "missing key", ref: "secret/sso/jwt#password", wantErr: ErrNotFound},
+		{name: "not a string", ref: "secret/sso/jwt#rotations", errText: `key "rotations" is not a string`},
+		{name: "bad response", ref: "secret/broken#key", errText: "decode vault response"},
+		{name: "no key", ref: "secret/sso/jwt", errText: "#key"},
+		{name: "no path", ref: "secret#signing_key", errText: "<mount>/<path>#<key>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.Fetch(context.Background(), tt.ref)
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Fetch() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.errText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Fatalf("Fetch() error = %v, want it to contain %q", err, tt.errText)
+				}
+			case err != nil:
+				t.Fatalf("Fetch() error = %v", err)
+			case got != tt.want:
+				t.Errorf("Fetch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVault_FetchWrongToken(t *testing.T) {
+	v := newTestVault(t)
+	v.token = "guess"
+
+	_, err := v.Fetch(context.Background(), "secret/sso/jwt#signing_key")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("Fetch() error = %v, want 403", err)
+	}
+}
+
+func TestResolver_Vault(t *testing.T) {
+	r := NewResolver()
+	r.Register("vault", newTestVault(t))
+
+	s := Secret("vault://secret/sso/jwt#signing_key")
+	if err := r.Resolve(context.Background(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Value() != "k3y" {
+		t.Errorf("Value() = %q, want k3y", s.Value())
+	}
+}
@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+
+	"sso/internal/config"
+)
+
+func TestSetup_StdoutFile(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	path := filepath.Join(t.TempDir(), "spans.json")
+
+	shutdown, err := Setup(context.Background(), config.TracingConfig{
+		Exporter:    ExporterStdout,
+		ServiceName: "sso-test",
+		SampleRatio: 1,
+		File:        path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := Tracer().Start(context.Background(), "test-span")
+	wantTraceID := span.SpanContext().TraceID().String()
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+
+	// The batcher flushes on shutdown, so the span must be in the file now.
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Name        string
+		SpanContext struct{ TraceID string }
+		Resource    []struct {
+			Key   string
+			Value struct{ Value any }
+		}
+	}
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&got); err != nil {
+		t.Fatalf("decode %q: %v", b, err)
+	}
+
+	if got.Name != "test-span" || got.SpanContext.TraceID != wantTraceID {
+		t.Errorf("span = %s %s, want test-span %s", got.Name, got.SpanContext.TraceID, wantTraceID)
+	}
+
+	var service any
+	for _, kv := range got.Resource {
+		if kv.Key == "service.name" {
+			service = kv.Value.Value
+		}
+	}
+	if service != "sso-test" {
+		t.Errorf("service.name = %v, want sso-test", service)
+	}
+
+	// A second shutdown only has the file left to close, which the first
+	// one already did.
+	if err := shutdown(context.Background()); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("second shutdown() error = %v, want %v", err, os.ErrClosed)
+	}
+}
+
+func TestSetup_None(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: ExporterNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otel.GetTracerProvider() != prev {
+		t.Error("exporter none replaced the tracer provider")
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+}
+
+func TestSetup_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.TracingConfig
+	}{
+		{name: "unknown exporter", cfg: config.TracingConfig{Exporter: "jaeger"}},
+		{
+			name: "unwritable file",
+			cfg:  config.TracingConfig{Exporter: ExporterStdout, File: filepath.Join(t.TempDir(), "missing", "spans.json")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Setup(context.Background(), tt.cfg); err == nil {
+				t.Fatal("Setup() error = nil, want error")
+			}
+		})
+	}
+}